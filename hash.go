@@ -0,0 +1,116 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+
+	"github.com/gtank/ristretto255/internal/radix51"
+)
+
+// expandMsgXMDBlockSize is the output size of the underlying hash function,
+// SHA-512, used by expandMessageXMD to size b_0, b_1, ... and compute ell.
+const expandMsgXMDBlockSize = sha512.Size
+
+// sha512InputBlockSize is SHA-512's input block size (RFC 9380 §5.3.1's
+// s_in_bytes), used only to size Z_pad. It must not be confused with
+// expandMsgXMDBlockSize, the digest size used everywhere else below.
+const sha512InputBlockSize = sha512.BlockSize
+
+// expandMessageXMD implements expand_message_xmd from RFC 9380 §5.3.1 using
+// SHA-512, producing n pseudorandom bytes tied to msg and domain-separated
+// by dst.
+func expandMessageXMD(msg, dst []byte, n int) []byte {
+	// Long DSTs are hashed down per RFC 9380 §5.3.3; with SHA-512 the
+	// replacement DST is 64 bytes long.
+	if len(dst) > 255 {
+		sum := sha512.Sum512(append([]byte("H2C-OVERSIZE-DST-"), dst...))
+		dst = sum[:]
+	}
+
+	ell := (n + expandMsgXMDBlockSize - 1) / expandMsgXMDBlockSize
+	if ell > 255 {
+		panic("ristretto255: expand_message_xmd: requested output too long")
+	}
+
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	var lIBStr [2]byte
+	binary.BigEndian.PutUint16(lIBStr[:], uint16(n))
+	zPad := make([]byte, sha512InputBlockSize)
+
+	h := sha512.New()
+	h.Write(zPad)
+	h.Write(msg)
+	h.Write(lIBStr[:])
+	h.Write([]byte{0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	prev := h.Sum(nil)
+
+	out := make([]byte, 0, ell*expandMsgXMDBlockSize)
+	out = append(out, prev...)
+
+	for i := 2; i <= ell; i++ {
+		xored := make([]byte, len(b0))
+		for j := range xored {
+			xored[j] = b0[j] ^ prev[j]
+		}
+
+		h.Reset()
+		h.Write(xored)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		prev = h.Sum(nil)
+		out = append(out, prev...)
+	}
+
+	return out[:n]
+}
+
+// HashToGroup implements the ristretto255_XMD:SHA-512_R255MAP_RO_ suite
+// from RFC 9380: it maps msg to a uniformly distributed Element, with dst
+// providing domain separation between callers and protocols.
+func HashToGroup(msg, dst []byte) *Element {
+	uniform := expandMessageXMD(msg, dst, 64)
+	e := new(Element)
+	e.FromUniformBytes(uniform)
+	return e
+}
+
+// EncodeToGroup implements the ristretto255_XMD:SHA-512_R255MAP_NU_ suite
+// from RFC 9380. Unlike HashToGroup, its output is not uniformly
+// distributed over the group, so it must not be used where that property
+// is required, such as a random oracle.
+func EncodeToGroup(msg, dst []byte) *Element {
+	nonuniform := expandMessageXMD(msg, dst, 32)
+
+	var buf [32]byte
+	copy(buf[:], nonuniform)
+	var f radix51.FieldElement
+	radix51.FeFromBytes(&f, &buf)
+
+	e := new(Element)
+	mapToPoint(&e.r, &f)
+	return e
+}
+
+// HashToScalar hashes msg to a uniformly distributed Scalar, with dst
+// providing domain separation, using the same expander as HashToGroup.
+// Almost every protocol built on HashToGroup also needs this, to derive
+// challenges or blinding factors in the same hash-to-curve suite.
+func HashToScalar(msg, dst []byte) *Scalar {
+	uniform := expandMessageXMD(msg, dst, 64)
+	s := new(Scalar)
+	s.FromUniformBytes(uniform)
+	return s
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestExpandMessageXMDLength(t *testing.T) {
+	msg, dst := []byte("a message"), []byte("a dst")
+	for _, n := range []int{1, 16, 32, 63, 64, 65, 128, 500} {
+		out := expandMessageXMD(msg, dst, n)
+		if len(out) != n {
+			t.Errorf("expandMessageXMD(_, _, %d) returned %d bytes", n, len(out))
+		}
+	}
+}
+
+func TestExpandMessageXMDDeterministicAndSeparated(t *testing.T) {
+	msg, dst := []byte("a message"), []byte("a dst")
+
+	a := expandMessageXMD(msg, dst, 64)
+	b := expandMessageXMD(msg, dst, 64)
+	if !bytes.Equal(a, b) {
+		t.Fatal("expandMessageXMD is not deterministic for identical inputs")
+	}
+
+	if bytes.Equal(a, expandMessageXMD([]byte("a different message"), dst, 64)) {
+		t.Fatal("expandMessageXMD gave the same output for two different messages")
+	}
+	if bytes.Equal(a, expandMessageXMD(msg, []byte("a different dst"), 64)) {
+		t.Fatal("expandMessageXMD gave the same output for two different dsts")
+	}
+}
+
+// TestExpandMessageXMDOversizeDST checks the "long DSTs are hashed down"
+// branch (RFC 9380 §5.3.3) against a second call that performs the same
+// hashing-down by hand: they must agree, since the only effect a DST over
+// 255 bytes should have is to be replaced by
+// SHA-512("H2C-OVERSIZE-DST-" || dst) before the rest of expand_message_xmd
+// runs unchanged.
+func TestExpandMessageXMDOversizeDST(t *testing.T) {
+	msg := []byte("a message")
+	longDST := bytes.Repeat([]byte{0x42}, 300)
+
+	sum := sha512.Sum512(append([]byte("H2C-OVERSIZE-DST-"), longDST...))
+	shortDST := sum[:]
+
+	got := expandMessageXMD(msg, longDST, 64)
+	want := expandMessageXMD(msg, shortDST, 64)
+	if !bytes.Equal(got, want) {
+		t.Fatal("expandMessageXMD with an oversize DST did not match hashing the DST down by hand")
+	}
+
+	// A 255-byte DST is exactly at the boundary and must NOT be hashed
+	// down.
+	dst255 := bytes.Repeat([]byte{0x42}, 255)
+	sum255 := sha512.Sum512(append([]byte("H2C-OVERSIZE-DST-"), dst255...))
+	if bytes.Equal(expandMessageXMD(msg, dst255, 64), expandMessageXMD(msg, sum255[:], 64)) {
+		t.Fatal("a 255-byte DST was hashed down, but the cutoff is >255")
+	}
+}
+
+func TestHashToGroupAndHashToScalar(t *testing.T) {
+	msg, dst := []byte("input"), []byte("dst")
+
+	e1 := HashToGroup(msg, dst)
+	e2 := HashToGroup(msg, dst)
+	if e1.Equal(e2) != 1 {
+		t.Fatal("HashToGroup is not deterministic for identical inputs")
+	}
+	if HashToGroup([]byte("other input"), dst).Equal(e1) == 1 {
+		t.Fatal("HashToGroup gave the same point for two different messages")
+	}
+	if HashToGroup(msg, []byte("other dst")).Equal(e1) == 1 {
+		t.Fatal("HashToGroup gave the same point for two different dsts")
+	}
+
+	s1 := HashToScalar(msg, dst)
+	s2 := HashToScalar(msg, dst)
+	if s1.Equal(s2) != 1 {
+		t.Fatal("HashToScalar is not deterministic for identical inputs")
+	}
+	if HashToScalar([]byte("other input"), dst).Equal(s1) == 1 {
+		t.Fatal("HashToScalar gave the same scalar for two different messages")
+	}
+}
+
+func TestEncodeToGroupDeterministicAndSeparated(t *testing.T) {
+	msg, dst := []byte("input"), []byte("dst")
+
+	e1 := EncodeToGroup(msg, dst)
+	e2 := EncodeToGroup(msg, dst)
+	if e1.Equal(e2) != 1 {
+		t.Fatal("EncodeToGroup is not deterministic for identical inputs")
+	}
+	if EncodeToGroup(msg, []byte("other dst")).Equal(e1) == 1 {
+		t.Fatal("EncodeToGroup gave the same point for two different dsts")
+	}
+	// EncodeToGroup and HashToGroup use distinct expansion lengths (32 vs
+	// 64 bytes) and so must not collide even for identical inputs.
+	if EncodeToGroup(msg, dst).Equal(HashToGroup(msg, dst)) == 1 {
+		t.Fatal("EncodeToGroup and HashToGroup produced the same point")
+	}
+}
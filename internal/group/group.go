@@ -0,0 +1,89 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package group implements the point arithmetic of the twisted Edwards
+// curve edwards25519 underlying ristretto255, in extended projective
+// (X:Y:Z:T) coordinates.
+package group
+
+import "github.com/gtank/ristretto255/internal/radix51"
+
+// ExtendedGroupElement is a point (X:Y:Z:T) on edwards25519 in extended
+// projective coordinates, representing the affine point (X/Z, Y/Z) with
+// T/Z = (X/Z)*(Y/Z). The zero value is NOT a valid point; use the
+// identity (0:1:1:0) explicitly where one is needed.
+type ExtendedGroupElement struct {
+	X, Y, Z, T radix51.FieldElement
+}
+
+// D is the edwards25519 curve parameter d in -x^2+y^2 = 1+d*x^2*y^2.
+var D radix51.FieldElement
+
+// d2 is 2*D, used by Add's add-2008-hwcd-3 formula.
+var d2 radix51.FieldElement
+
+// B is the edwards25519 basepoint.
+var B ExtendedGroupElement
+
+func init() {
+	dBytes := [32]byte{
+		0xa3, 0x78, 0x59, 0x13, 0xca, 0x4d, 0xeb, 0x75, 0xab, 0xd8, 0x41, 0x41, 0x4d, 0x0a, 0x70, 0x00,
+		0x98, 0xe8, 0x79, 0x77, 0x79, 0x40, 0xc7, 0x8c, 0x73, 0xfe, 0x6f, 0x2b, 0xee, 0x6c, 0x03, 0x52,
+	}
+	radix51.FeFromBytes(&D, &dBytes)
+	radix51.FeAdd(&d2, &D, &D)
+
+	bxBytes := [32]byte{
+		0x1a, 0xd5, 0x25, 0x8f, 0x60, 0x2d, 0x56, 0xc9, 0xb2, 0xa7, 0x25, 0x95, 0x60, 0xc7, 0x2c, 0x69,
+		0x5c, 0xdc, 0xd6, 0xfd, 0x31, 0xe2, 0xa4, 0xc0, 0xfe, 0x53, 0x6e, 0xcd, 0xd3, 0x36, 0x69, 0x21,
+	}
+	byBytes := [32]byte{
+		0x58, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+		0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	}
+	btBytes := [32]byte{
+		0xa3, 0xdd, 0xb7, 0xa5, 0xb3, 0x8a, 0xde, 0x6d, 0xf5, 0x52, 0x51, 0x77, 0x80, 0x9f, 0xf0, 0x20,
+		0x7d, 0xe3, 0xab, 0x64, 0x8e, 0x4e, 0xea, 0x66, 0x65, 0x76, 0x8b, 0xd7, 0x0f, 0x5f, 0x87, 0x67,
+	}
+
+	radix51.FeFromBytes(&B.X, &bxBytes)
+	radix51.FeFromBytes(&B.Y, &byBytes)
+	radix51.FeFromBytes(&B.T, &btBytes)
+	radix51.FeOne(&B.Z)
+}
+
+// Add sets e = p + q and returns e, using the add-2008-hwcd-3 formula,
+// which is complete (works for doubling, and for any p, q including the
+// identity) because a = -1 on this curve.
+func (e *ExtendedGroupElement) Add(p, q *ExtendedGroupElement) *ExtendedGroupElement {
+	var a, b, c, dd, ee, f, g, h radix51.FieldElement
+
+	var t0 radix51.FieldElement
+	radix51.FeSub(&a, &p.Y, &p.X)
+	radix51.FeSub(&t0, &q.Y, &q.X)
+	radix51.FeMul(&a, &a, &t0)
+
+	radix51.FeAdd(&b, &p.Y, &p.X)
+	radix51.FeAdd(&t0, &q.Y, &q.X)
+	radix51.FeMul(&b, &b, &t0)
+
+	radix51.FeMul(&c, &p.T, &d2)
+	radix51.FeMul(&c, &c, &q.T)
+
+	radix51.FeMul(&dd, &p.Z, &q.Z)
+	radix51.FeAdd(&dd, &dd, &dd)
+
+	radix51.FeSub(&ee, &b, &a)
+	radix51.FeSub(&f, &dd, &c)
+	radix51.FeAdd(&g, &dd, &c)
+	radix51.FeAdd(&h, &b, &a)
+
+	radix51.FeMul(&e.X, &ee, &f)
+	radix51.FeMul(&e.Y, &g, &h)
+	radix51.FeMul(&e.T, &ee, &h)
+	radix51.FeMul(&e.Z, &f, &g)
+
+	return e
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/gtank/ristretto255/internal/radix51"
+)
+
+var pBig, _ = new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+var dBig, _ = new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+	return out
+}
+
+// affine converts e to affine (x, y) coordinates using an independent
+// big.Int modular inverse, checking along the way that e satisfies the
+// curve equation and the X*Y = T*Z invariant extended coordinates rely
+// on.
+func affine(t *testing.T, e *ExtendedGroupElement) (x, y *big.Int) {
+	t.Helper()
+	var xb, yb, zb, tb [32]byte
+	radix51.FeToBytes(&xb, &e.X)
+	radix51.FeToBytes(&yb, &e.Y)
+	radix51.FeToBytes(&zb, &e.Z)
+	radix51.FeToBytes(&tb, &e.T)
+
+	X := new(big.Int).SetBytes(reverse(xb[:]))
+	Y := new(big.Int).SetBytes(reverse(yb[:]))
+	Z := new(big.Int).SetBytes(reverse(zb[:]))
+	T := new(big.Int).SetBytes(reverse(tb[:]))
+
+	zInv := new(big.Int).ModInverse(Z, pBig)
+	if zInv == nil {
+		t.Fatal("Z has no inverse mod p")
+	}
+	x = new(big.Int).Mod(new(big.Int).Mul(X, zInv), pBig)
+	y = new(big.Int).Mod(new(big.Int).Mul(Y, zInv), pBig)
+
+	wantT := new(big.Int).Mod(new(big.Int).Mul(x, y), pBig)
+	gotT := new(big.Int).Mod(new(big.Int).Mul(T, zInv), pBig)
+	if wantT.Cmp(gotT) != 0 {
+		t.Fatalf("T invariant violated: x*y = %v, T/Z = %v", wantT, gotT)
+	}
+
+	lhs := new(big.Int).Mod(new(big.Int).Sub(new(big.Int).Mul(y, y), new(big.Int).Mul(x, x)), pBig)
+	xxyy := new(big.Int).Mod(new(big.Int).Mul(new(big.Int).Mul(x, x), new(big.Int).Mul(y, y)), pBig)
+	rhs := new(big.Int).Mod(new(big.Int).Add(big.NewInt(1), new(big.Int).Mul(dBig, xxyy)), pBig)
+	if lhs.Cmp(rhs) != 0 {
+		t.Fatalf("curve equation violated at (%v, %v)", x, y)
+	}
+	return x, y
+}
+
+// affineAdd is an independent reference for twisted Edwards addition
+// (a = -1) in affine coordinates, checked against ExtendedGroupElement.Add.
+func affineAdd(x1, y1, x2, y2 *big.Int) (x3, y3 *big.Int) {
+	x1y2 := new(big.Int).Mul(x1, y2)
+	y1x2 := new(big.Int).Mul(y1, x2)
+	y1y2 := new(big.Int).Mul(y1, y2)
+	x1x2 := new(big.Int).Mul(x1, x2)
+	dx1x2y1y2 := new(big.Int).Mod(new(big.Int).Mul(dBig, new(big.Int).Mul(x1x2, y1y2)), pBig)
+
+	xNum := new(big.Int).Mod(new(big.Int).Add(x1y2, y1x2), pBig)
+	xDen := new(big.Int).Mod(new(big.Int).Add(big.NewInt(1), dx1x2y1y2), pBig)
+	yNum := new(big.Int).Mod(new(big.Int).Add(y1y2, x1x2), pBig)
+	yDen := new(big.Int).Mod(new(big.Int).Sub(big.NewInt(1), dx1x2y1y2), pBig)
+
+	x3 = new(big.Int).Mod(new(big.Int).Mul(xNum, new(big.Int).ModInverse(xDen, pBig)), pBig)
+	y3 = new(big.Int).Mod(new(big.Int).Mul(yNum, new(big.Int).ModInverse(yDen, pBig)), pBig)
+	return x3, y3
+}
+
+func TestBasepointOnCurve(t *testing.T) {
+	affine(t, &B)
+}
+
+func TestDoublingAgainstAffineReference(t *testing.T) {
+	p := B
+	x, y := affine(t, &p)
+	for i := 0; i < 32; i++ {
+		var next ExtendedGroupElement
+		next.Add(&p, &p)
+		gotX, gotY := affine(t, &next)
+
+		wantX, wantY := affineAdd(x, y, x, y)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			t.Fatalf("round %d: doubling via Add disagreed with affine reference", i)
+		}
+		p, x, y = next, gotX, gotY
+	}
+}
+
+// scalarMultSmall computes n*B by repeated doubling and conditional
+// addition, to build up distinct, non-trivial test points without
+// depending on the root package's scalar multiplication.
+func scalarMultSmall(n int) *ExtendedGroupElement {
+	acc := &ExtendedGroupElement{}
+	radix51.FeOne(&acc.Y)
+	radix51.FeOne(&acc.Z)
+
+	base := B
+	for n > 0 {
+		if n&1 == 1 {
+			acc.Add(acc, &base)
+		}
+		base.Add(&base, &base)
+		n >>= 1
+	}
+	return acc
+}
+
+func TestAdditionAgainstAffineReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		p := scalarMultSmall(1 + rnd.Intn(5000))
+		q := scalarMultSmall(1 + rnd.Intn(5000))
+
+		px, py := affine(t, p)
+		qx, qy := affine(t, q)
+
+		var sum ExtendedGroupElement
+		sum.Add(p, q)
+		gotX, gotY := affine(t, &sum)
+
+		wantX, wantY := affineAdd(px, py, qx, qy)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			t.Fatalf("round %d: Add disagreed with affine reference", i)
+		}
+	}
+}
+
+func TestIdentityIsAdditiveIdentity(t *testing.T) {
+	var id ExtendedGroupElement
+	radix51.FeOne(&id.Y)
+	radix51.FeOne(&id.Z)
+
+	p := scalarMultSmall(12345)
+	px, py := affine(t, p)
+
+	var sum ExtendedGroupElement
+	sum.Add(p, &id)
+	gotX, gotY := affine(t, &sum)
+	if gotX.Cmp(px) != 0 || gotY.Cmp(py) != 0 {
+		t.Fatal("P + identity != P")
+	}
+}
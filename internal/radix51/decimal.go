@@ -0,0 +1,28 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package radix51
+
+import "math/big"
+
+// FeFromDecimal parses the base-10 string s as a field element in [0, p).
+// It is only used to define compile-time constants, so it panics on
+// malformed input rather than returning an error.
+func FeFromDecimal(s string) *FieldElement {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("radix51: invalid decimal constant " + s)
+	}
+
+	var buf [32]byte
+	n.FillBytes(buf[:])
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+
+	fe := new(FieldElement)
+	FeFromBytes(fe, &buf)
+	return fe
+}
@@ -0,0 +1,277 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package radix51 implements arithmetic modulo p = 2^255-19, the field
+// edwards25519 (and so ristretto255) is defined over.
+package radix51
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/bits"
+)
+
+const maskLow51Bits = (uint64(1) << 51) - 1
+
+// FieldElement represents an element of GF(2^255-19) as five 51-bit limbs
+// in little-endian order: the value is l0 + l1*2^51 + l2*2^102 + l3*2^153 +
+// l4*2^204. Limbs are kept at or below 2^51 between calls, but the represented
+// value is only reduced to its canonical range [0, p) on demand, by
+// FeToBytes.
+type FieldElement struct {
+	l0, l1, l2, l3, l4 uint64
+}
+
+func FeZero(out *FieldElement) {
+	*out = FieldElement{}
+}
+
+func FeOne(out *FieldElement) {
+	*out = FieldElement{l0: 1}
+}
+
+// carryPropagate reduces every limb of e below 2^51, wrapping any carry
+// out of the top limb back into the bottom one multiplied by 19, since
+// 2^255 = 19 (mod p). The final carry out of l0 is added to l1 without a
+// further carry, so l1 can be left at exactly 2^51.
+func carryPropagate(e *FieldElement) {
+	c := e.l0 >> 51
+	e.l0 &= maskLow51Bits
+	e.l1 += c
+
+	c = e.l1 >> 51
+	e.l1 &= maskLow51Bits
+	e.l2 += c
+
+	c = e.l2 >> 51
+	e.l2 &= maskLow51Bits
+	e.l3 += c
+
+	c = e.l3 >> 51
+	e.l3 &= maskLow51Bits
+	e.l4 += c
+
+	c = e.l4 >> 51
+	e.l4 &= maskLow51Bits
+	e.l0 += c * 19
+
+	c = e.l0 >> 51
+	e.l0 &= maskLow51Bits
+	e.l1 += c
+}
+
+func FeAdd(out, a, b *FieldElement) {
+	out.l0 = a.l0 + b.l0
+	out.l1 = a.l1 + b.l1
+	out.l2 = a.l2 + b.l2
+	out.l3 = a.l3 + b.l3
+	out.l4 = a.l4 + b.l4
+	carryPropagate(out)
+}
+
+// p0 and pRest are the limbs of p = 2^255-19: p0 is the bottom limb, pRest
+// is every other limb (they're all 2^51-1).
+const (
+	p0    = (uint64(1) << 51) - 19
+	pRest = (uint64(1) << 51) - 1
+)
+
+// FeSub sets out = a - b. It adds in 2*p (limb by limb) before subtracting
+// so that every limb stays non-negative; carryPropagate then re-normalizes
+// the result, which is unaffected mod p by adding a multiple of p.
+func FeSub(out, a, b *FieldElement) {
+	out.l0 = a.l0 + 2*p0 - b.l0
+	out.l1 = a.l1 + 2*pRest - b.l1
+	out.l2 = a.l2 + 2*pRest - b.l2
+	out.l3 = a.l3 + 2*pRest - b.l3
+	out.l4 = a.l4 + 2*pRest - b.l4
+	carryPropagate(out)
+}
+
+func FeNeg(out, a *FieldElement) {
+	var zero FieldElement
+	FeSub(out, &zero, a)
+}
+
+// wideLimb accumulates a sum of products of two limbs, one of which may be
+// pre-multiplied by 19 (each product fits in about 107 bits), without
+// overflowing, using the standard hi:lo uint64 pair for a 128-bit value.
+type wideLimb struct{ hi, lo uint64 }
+
+func (w *wideLimb) addProduct(a, b uint64) {
+	hi, lo := bits.Mul64(a, b)
+	var c uint64
+	w.lo, c = bits.Add64(w.lo, lo, 0)
+	w.hi, _ = bits.Add64(w.hi, hi, c)
+}
+
+// shiftAndCarry splits off the low 51 bits of w and returns the rest,
+// shifted down by 51, as the carry into the next limb.
+func (w wideLimb) shiftAndCarry() (low51, carry uint64) {
+	low51 = w.lo & maskLow51Bits
+	carry = (w.lo >> 51) | (w.hi << 13)
+	return
+}
+
+// FeMul sets out = a * b mod p, using schoolbook multiplication over the
+// five 51-bit limbs: cross terms with weight >= 2^255 are folded back in
+// multiplied by 19, since 2^255 = 19 (mod p).
+func FeMul(out, a, b *FieldElement) {
+	a0, a1, a2, a3, a4 := a.l0, a.l1, a.l2, a.l3, a.l4
+	b0, b1, b2, b3, b4 := b.l0, b.l1, b.l2, b.l3, b.l4
+
+	b1_19 := b1 * 19
+	b2_19 := b2 * 19
+	b3_19 := b3 * 19
+	b4_19 := b4 * 19
+
+	var t0, t1, t2, t3, t4 wideLimb
+
+	t0.addProduct(a0, b0)
+	t0.addProduct(a1, b4_19)
+	t0.addProduct(a2, b3_19)
+	t0.addProduct(a3, b2_19)
+	t0.addProduct(a4, b1_19)
+
+	t1.addProduct(a0, b1)
+	t1.addProduct(a1, b0)
+	t1.addProduct(a2, b4_19)
+	t1.addProduct(a3, b3_19)
+	t1.addProduct(a4, b2_19)
+
+	t2.addProduct(a0, b2)
+	t2.addProduct(a1, b1)
+	t2.addProduct(a2, b0)
+	t2.addProduct(a3, b4_19)
+	t2.addProduct(a4, b3_19)
+
+	t3.addProduct(a0, b3)
+	t3.addProduct(a1, b2)
+	t3.addProduct(a2, b1)
+	t3.addProduct(a3, b0)
+	t3.addProduct(a4, b4_19)
+
+	t4.addProduct(a0, b4)
+	t4.addProduct(a1, b3)
+	t4.addProduct(a2, b2)
+	t4.addProduct(a3, b1)
+	t4.addProduct(a4, b0)
+
+	r0, c0 := t0.shiftAndCarry()
+	r1, c1 := t1.shiftAndCarry()
+	r2, c2 := t2.shiftAndCarry()
+	r3, c3 := t3.shiftAndCarry()
+	r4, c4 := t4.shiftAndCarry()
+
+	out.l0 = r0 + c4*19
+	out.l1 = r1 + c0
+	out.l2 = r2 + c1
+	out.l3 = r3 + c2
+	out.l4 = r4 + c3
+	carryPropagate(out)
+}
+
+// FeSquare sets out = a * a mod p.
+func FeSquare(out, a *FieldElement) {
+	FeMul(out, a, a)
+}
+
+// FeToBytes sets out to the little-endian encoding of a's canonical
+// representative in [0, p).
+func FeToBytes(out *[32]byte, a *FieldElement) {
+	t := *a
+	carryPropagate(&t)
+
+	// q is 1 if t >= p and 0 otherwise: t+19 overflows 2^255 exactly when
+	// t >= 2^255-19 = p.
+	q := (t.l0 + 19) >> 51
+	q = (t.l1 + q) >> 51
+	q = (t.l2 + q) >> 51
+	q = (t.l3 + q) >> 51
+	q = (t.l4 + q) >> 51
+
+	// Adding 19*q and discarding the carry out of the top limb computes
+	// t - p when q == 1 (since t + 19 - 2^255 = t - p), and leaves t
+	// unchanged when q == 0.
+	t.l0 += 19 * q
+	t.l1 += t.l0 >> 51
+	t.l0 &= maskLow51Bits
+	t.l2 += t.l1 >> 51
+	t.l1 &= maskLow51Bits
+	t.l3 += t.l2 >> 51
+	t.l2 &= maskLow51Bits
+	t.l4 += t.l3 >> 51
+	t.l3 &= maskLow51Bits
+	t.l4 &= maskLow51Bits
+
+	w0 := t.l0 | (t.l1 << 51)
+	w1 := (t.l1 >> 13) | (t.l2 << 38)
+	w2 := (t.l2 >> 26) | (t.l3 << 25)
+	w3 := (t.l3 >> 39) | (t.l4 << 12)
+
+	binary.LittleEndian.PutUint64(out[0:8], w0)
+	binary.LittleEndian.PutUint64(out[8:16], w1)
+	binary.LittleEndian.PutUint64(out[16:24], w2)
+	binary.LittleEndian.PutUint64(out[24:32], w3)
+}
+
+// FeFromBytes sets out to the value of the little-endian 32-byte encoding
+// in, ignoring (not rejecting) the unused top bit. It does not reduce
+// non-canonical inputs (x >= p): callers that must reject those compare
+// FeToBytes(FeFromBytes(x)) against x, as Element.Decode does.
+func FeFromBytes(out *FieldElement, in *[32]byte) {
+	w0 := binary.LittleEndian.Uint64(in[0:8])
+	w1 := binary.LittleEndian.Uint64(in[8:16])
+	w2 := binary.LittleEndian.Uint64(in[16:24])
+	w3 := binary.LittleEndian.Uint64(in[24:32])
+	w3 &= (uint64(1) << 63) - 1
+
+	out.l0 = w0 & maskLow51Bits
+	out.l1 = ((w0 >> 51) | (w1 << 13)) & maskLow51Bits
+	out.l2 = ((w1 >> 38) | (w2 << 26)) & maskLow51Bits
+	out.l3 = ((w2 >> 25) | (w3 << 39)) & maskLow51Bits
+	out.l4 = (w3 >> 12) & maskLow51Bits
+}
+
+// FeEqual returns 1 if a and b are equal mod p, and 0 otherwise.
+func FeEqual(a, b *FieldElement) int {
+	var sa, sb [32]byte
+	FeToBytes(&sa, a)
+	FeToBytes(&sb, b)
+	return subtle.ConstantTimeCompare(sa[:], sb[:])
+}
+
+// FeIsNegative returns 1 if a's canonical representative is odd, and 0
+// otherwise. This is the sign convention ristretto255 uses throughout.
+func FeIsNegative(a *FieldElement) int {
+	var s [32]byte
+	FeToBytes(&s, a)
+	return int(s[0] & 1)
+}
+
+// FeSelect sets out = a if cond == 1, and out = b if cond == 0.
+func FeSelect(out, a, b *FieldElement, cond int) {
+	m := uint64(0) - uint64(cond&1)
+	out.l0 = (a.l0 & m) | (b.l0 &^ m)
+	out.l1 = (a.l1 & m) | (b.l1 &^ m)
+	out.l2 = (a.l2 & m) | (b.l2 &^ m)
+	out.l3 = (a.l3 & m) | (b.l3 &^ m)
+	out.l4 = (a.l4 & m) | (b.l4 &^ m)
+}
+
+// FeCondNeg negates e in place if cond == 1, and leaves it unchanged if
+// cond == 0.
+func FeCondNeg(e *FieldElement, cond int) {
+	var neg FieldElement
+	FeNeg(&neg, e)
+	FeSelect(e, &neg, e, cond)
+}
+
+// FeAbs sets out to a if a is non-negative, and to -a otherwise.
+func FeAbs(out, a *FieldElement) {
+	var neg FieldElement
+	FeNeg(&neg, a)
+	FeSelect(out, &neg, a, FeIsNegative(a))
+}
@@ -0,0 +1,233 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package radix51
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// pBig is p = 2^255-19, as a math/big value to check this package's
+// fixed-width arithmetic against.
+var pBig, _ = new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+	return out
+}
+
+func bigFromFe(e *FieldElement) *big.Int {
+	var b [32]byte
+	FeToBytes(&b, e)
+	return new(big.Int).SetBytes(reverse(b[:]))
+}
+
+func feFromBig(x *big.Int) *FieldElement {
+	x = new(big.Int).Mod(x, pBig)
+	var buf [32]byte
+	copy(buf[:], reverse(x.FillBytes(make([]byte, 32))))
+	var e FieldElement
+	FeFromBytes(&e, &buf)
+	return &e
+}
+
+func randomBig(rnd *rand.Rand) *big.Int {
+	buf := make([]byte, 32)
+	rnd.Read(buf)
+	return new(big.Int).SetBytes(buf)
+}
+
+func TestAddSubMulAgainstBigInt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		aBig, bBig := randomBig(rnd), randomBig(rnd)
+		a, b := feFromBig(aBig), feFromBig(bBig)
+
+		var sum, diff, prod, sq FieldElement
+		FeAdd(&sum, a, b)
+		FeSub(&diff, a, b)
+		FeMul(&prod, a, b)
+		FeSquare(&sq, a)
+
+		if got, want := bigFromFe(&sum), new(big.Int).Mod(new(big.Int).Add(aBig, bBig), pBig); got.Cmp(want) != 0 {
+			t.Fatalf("Add(%v, %v) = %v, want %v", aBig, bBig, got, want)
+		}
+		if got, want := bigFromFe(&diff), new(big.Int).Mod(new(big.Int).Sub(aBig, bBig), pBig); got.Cmp(want) != 0 {
+			t.Fatalf("Sub(%v, %v) = %v, want %v", aBig, bBig, got, want)
+		}
+		if got, want := bigFromFe(&prod), new(big.Int).Mod(new(big.Int).Mul(aBig, bBig), pBig); got.Cmp(want) != 0 {
+			t.Fatalf("Mul(%v, %v) = %v, want %v", aBig, bBig, got, want)
+		}
+		if got, want := bigFromFe(&sq), new(big.Int).Mod(new(big.Int).Mul(aBig, aBig), pBig); got.Cmp(want) != 0 {
+			t.Fatalf("Square(%v) = %v, want %v", aBig, got, want)
+		}
+	}
+}
+
+func TestNegAgainstBigInt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 5000; i++ {
+		aBig := randomBig(rnd)
+		a := feFromBig(aBig)
+		var neg FieldElement
+		FeNeg(&neg, a)
+		want := new(big.Int).Mod(new(big.Int).Neg(aBig), pBig)
+		if got := bigFromFe(&neg); got.Cmp(want) != 0 {
+			t.Fatalf("Neg(%v) = %v, want %v", aBig, got, want)
+		}
+	}
+}
+
+func TestFromBytesToBytesRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 5000; i++ {
+		aBig := randomBig(rnd)
+		aBig.Mod(aBig, pBig)
+		a := feFromBig(aBig)
+
+		var b [32]byte
+		FeToBytes(&b, a)
+		if got := new(big.Int).SetBytes(reverse(b[:])); got.Cmp(aBig) != 0 {
+			t.Fatalf("FeToBytes(%v) = %v, want %v", aBig, got, aBig)
+		}
+
+		var rt FieldElement
+		FeFromBytes(&rt, &b)
+		if got := bigFromFe(&rt); got.Cmp(aBig) != 0 {
+			t.Fatalf("round trip through FromBytes/ToBytes: got %v, want %v", got, aBig)
+		}
+	}
+}
+
+func TestIsNegativeParity(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	for i := 0; i < 5000; i++ {
+		aBig := randomBig(rnd)
+		aBig.Mod(aBig, pBig)
+		a := feFromBig(aBig)
+		want := int(aBig.Bit(0))
+		if got := FeIsNegative(a); got != want {
+			t.Fatalf("IsNegative(%v) = %d, want %d", aBig, got, want)
+		}
+	}
+}
+
+func TestCondNegAndAbs(t *testing.T) {
+	rnd := rand.New(rand.NewSource(5))
+	for i := 0; i < 5000; i++ {
+		aBig := randomBig(rnd)
+		aBig.Mod(aBig, pBig)
+		a := feFromBig(aBig)
+
+		neg0 := *a
+		FeCondNeg(&neg0, 0)
+		if bigFromFe(&neg0).Cmp(aBig) != 0 {
+			t.Fatal("CondNeg(0) changed the value")
+		}
+
+		neg1 := *a
+		FeCondNeg(&neg1, 1)
+		want := new(big.Int).Mod(new(big.Int).Neg(aBig), pBig)
+		if bigFromFe(&neg1).Cmp(want) != 0 {
+			t.Fatalf("CondNeg(1) = %v, want %v", bigFromFe(&neg1), want)
+		}
+
+		var abs FieldElement
+		FeAbs(&abs, a)
+		if FeIsNegative(&abs) != 0 {
+			t.Fatalf("Abs(%v) is still negative", aBig)
+		}
+		negA := new(big.Int).Mod(new(big.Int).Neg(aBig), pBig)
+		gotAbs := bigFromFe(&abs)
+		if gotAbs.Cmp(aBig) != 0 && gotAbs.Cmp(negA) != 0 {
+			t.Fatalf("Abs(%v) = %v is neither a nor -a", aBig, gotAbs)
+		}
+	}
+}
+
+func TestSelectAndEqual(t *testing.T) {
+	rnd := rand.New(rand.NewSource(6))
+	for i := 0; i < 1000; i++ {
+		a := feFromBig(randomBig(rnd))
+		b := feFromBig(randomBig(rnd))
+
+		var sel FieldElement
+		FeSelect(&sel, a, b, 1)
+		if FeEqual(&sel, a) != 1 {
+			t.Fatal("Select(1) did not return a")
+		}
+		FeSelect(&sel, a, b, 0)
+		if FeEqual(&sel, b) != 1 {
+			t.Fatal("Select(0) did not return b")
+		}
+	}
+}
+
+func TestOneAndZero(t *testing.T) {
+	var one, zero FieldElement
+	FeOne(&one)
+	FeZero(&zero)
+	if bigFromFe(&one).Cmp(big.NewInt(1)) != 0 {
+		t.Fatal("One() != 1")
+	}
+	if bigFromFe(&zero).Sign() != 0 {
+		t.Fatal("Zero() != 0")
+	}
+}
+
+// TestFeSqrtRatioAgainstBigInt checks FeSqrtRatio's return value against
+// Euler's criterion, and its result against the ratio it claims to be a
+// square root of (or, when the ratio is a non-residue, against i times
+// that ratio).
+func TestFeSqrtRatioAgainstBigInt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	sqrtM1Big := bigFromFe(&sqrtM1)
+
+	for i := 0; i < 2000; i++ {
+		uBig := randomBig(rnd)
+		uBig.Mod(uBig, pBig)
+		vBig := randomBig(rnd)
+		vBig.Mod(vBig, pBig)
+		if vBig.Sign() == 0 {
+			continue
+		}
+
+		u, v := feFromBig(uBig), feFromBig(vBig)
+
+		var out FieldElement
+		wasSquare := FeSqrtRatio(&out, u, v)
+
+		vInv := new(big.Int).ModInverse(vBig, pBig)
+		ratio := new(big.Int).Mod(new(big.Int).Mul(uBig, vInv), pBig)
+
+		exp := new(big.Int).Rsh(new(big.Int).Sub(pBig, big.NewInt(1)), 1)
+		isSquare := new(big.Int).Exp(ratio, exp, pBig).Cmp(big.NewInt(1)) == 0
+		if isSquare != (wasSquare == 1) {
+			t.Fatalf("u=%v v=%v: FeSqrtRatio returned wasSquare=%d, want %v", uBig, vBig, wasSquare, isSquare)
+		}
+
+		gotBig := bigFromFe(&out)
+		if gotBig.Bit(0) != 0 {
+			t.Fatalf("u=%v v=%v: FeSqrtRatio result %v is not the non-negative root", uBig, vBig, gotBig)
+		}
+
+		gotSq := new(big.Int).Mod(new(big.Int).Mul(gotBig, gotBig), pBig)
+		if wasSquare == 1 {
+			if gotSq.Cmp(ratio) != 0 {
+				t.Fatalf("u=%v v=%v: out^2 = %v, want u/v = %v", uBig, vBig, gotSq, ratio)
+			}
+		} else {
+			wantSq := new(big.Int).Mod(new(big.Int).Mul(sqrtM1Big, ratio), pBig)
+			if gotSq.Cmp(wantSq) != 0 {
+				t.Fatalf("u=%v v=%v: out^2 = %v, want i*u/v = %v", uBig, vBig, gotSq, wantSq)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package radix51
+
+// sqrtM1 is a square root of -1 modulo p, used by FeSqrtRatio to detect and
+// correct the two ways a candidate square root can be wrong.
+var sqrtM1 = *FeFromDecimal(
+	"19681161376707505956807079304988542015446066515923890162744021073123829784752")
+
+// fePow22523 sets out = z^((p-5)/8), the exponent used by the constant-time
+// square-root computation below. It is derived from x/crypto/ed25519/internal/edwards25519.
+func fePow22523(out, z *FieldElement) {
+	var t0, t1, t2 FieldElement
+
+	FeSquare(&t0, z)
+	FeSquare(&t1, &t0)
+	FeSquare(&t1, &t1)
+	FeMul(&t1, z, &t1)
+	FeMul(&t0, &t0, &t1)
+	FeSquare(&t0, &t0)
+	FeMul(&t0, &t1, &t0)
+	FeSquare(&t1, &t0)
+	for i := 0; i < 4; i++ {
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t0, &t1, &t0)
+	FeSquare(&t1, &t0)
+	for i := 0; i < 9; i++ {
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t1, &t1, &t0)
+	FeSquare(&t2, &t1)
+	for i := 0; i < 19; i++ {
+		FeSquare(&t2, &t2)
+	}
+	FeMul(&t1, &t2, &t1)
+	for i := 0; i < 10; i++ {
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t0, &t1, &t0)
+	FeSquare(&t1, &t0)
+	for i := 0; i < 49; i++ {
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t1, &t1, &t0)
+	FeSquare(&t2, &t1)
+	for i := 0; i < 99; i++ {
+		FeSquare(&t2, &t2)
+	}
+	FeMul(&t1, &t2, &t1)
+	for i := 0; i < 50; i++ {
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t0, &t1, &t0)
+	FeSquare(&t0, &t0)
+	FeSquare(&t0, &t0)
+	FeMul(out, &t0, z)
+}
+
+// FeSqrtRatio sets out to a square root of u/v, returning 1 if u/v is
+// indeed a square, and 0 if it is not; in the latter case out is instead
+// set to a square root of i*u/v, where i is a fixed non-square. In both
+// cases out is the non-negative one of the two candidate roots. This is
+// SQRT_RATIO_M1 from draft-hdevalence-cfrg-ristretto-00 §3.1.3.
+func FeSqrtRatio(out, u, v *FieldElement) int {
+	var v3, v7, r, check, uNeg FieldElement
+
+	FeSquare(&v3, v)
+	FeMul(&v3, &v3, v) // v3 = v^3
+	FeSquare(&v7, &v3)
+	FeMul(&v7, &v7, v) // v7 = v^7
+
+	// r = (u * v3) * (u * v7)^((p-5)/8)
+	var uv3, uv7 FieldElement
+	FeMul(&uv3, u, &v3)
+	FeMul(&uv7, u, &v7)
+	fePow22523(&uv7, &uv7)
+	FeMul(&r, &uv3, &uv7)
+
+	// check = v * r^2, which should equal u if r is a square root of u/v.
+	FeSquare(&check, &r)
+	FeMul(&check, &check, v)
+
+	FeNeg(&uNeg, u)
+	correctSignSqrt := FeEqual(&check, u)
+	flippedSignSqrt := FeEqual(&check, &uNeg)
+	FeMul(&uNeg, &uNeg, &sqrtM1)
+	flippedSignSqrtI := FeEqual(&check, &uNeg)
+
+	var rPrime FieldElement
+	FeMul(&rPrime, &r, &sqrtM1)
+	FeSelect(&r, &rPrime, &r, flippedSignSqrt|flippedSignSqrtI)
+
+	FeAbs(&r, &r)
+	*out = r
+
+	return correctSignSqrt | flippedSignSqrt
+}
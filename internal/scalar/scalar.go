@@ -0,0 +1,318 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scalar implements arithmetic modulo the ristretto255/ed25519
+// group order
+//
+//	l = 2^252 + 27742317777372353535851937790883648493
+//
+// Scalars are held as 32-byte little-endian arrays and arithmetic is
+// implemented over four 64-bit limbs. isReduced (and so SetCanonicalBytes)
+// and Equal run in constant time. Add, Subtract, Multiply, MulAdd, Reduce
+// and Invert branch on carries, borrows and comparisons against l, so they
+// do not run in constant time with respect to the values involved.
+package scalar
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Scalar is an integer modulo l, held as 32 little-endian bytes. The zero
+// value is a valid, reduced representation of 0.
+type Scalar [32]byte
+
+// l, as 32 little-endian bytes.
+var l = Scalar{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+	0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+var lLimbs = bytesToLimbs((*[32]byte)(&l))
+
+// lLimbs5 is lLimbs zero-extended to five 64-bit limbs, for use in the
+// 320-bit intermediate values of barrettReduce.
+var lLimbs5 = [5]uint64{lLimbs[0], lLimbs[1], lLimbs[2], lLimbs[3], 0}
+
+// mu is floor(2^512 / l), precomputed for Barrett reduction of the 512-bit
+// products produced by mulLimbs and the wide hash outputs passed to Reduce.
+var mu = [5]uint64{
+	0xed9ce5a30a2c131b,
+	0x2106215d086329a7,
+	0xffffffffffffffeb,
+	0xffffffffffffffff,
+	0xf,
+}
+
+// Zero sets s = 0 and returns s.
+func (s *Scalar) Zero() *Scalar {
+	*s = Scalar{}
+	return s
+}
+
+// One sets s = 1 and returns s.
+func (s *Scalar) One() *Scalar {
+	*s = Scalar{}
+	s[0] = 1
+	return s
+}
+
+// Bytes returns the canonical 32-byte little-endian encoding of s.
+func (s *Scalar) Bytes() [32]byte {
+	return [32]byte(*s)
+}
+
+// SetCanonicalBytes sets s = x if x is already the canonical little-endian
+// encoding of an integer in [0, l), and returns (s, true). Otherwise it
+// returns (s, false) and leaves s unmodified.
+func SetCanonicalBytes(s *Scalar, x [32]byte) (*Scalar, bool) {
+	if !isReduced(&x) {
+		return s, false
+	}
+	*s = Scalar(x)
+	return s, true
+}
+
+// isReduced reports whether x represents an integer strictly less than l, in
+// constant time with respect to x.
+func isReduced(x *[32]byte) bool {
+	var lt, gt uint32
+	for i := 31; i >= 0; i-- {
+		// Once a higher byte has already differed, these updates are
+		// no-ops: lt and gt are sticky, so only the first difference
+		// (scanning from the most significant byte) counts.
+		xi, li := uint32(x[i]), uint32(l[i])
+		lt |= (1 - gt) & (1 - lt) & ctLess(xi, li)
+		gt |= (1 - gt) & (1 - lt) & ctLess(li, xi)
+	}
+	return lt == 1
+}
+
+// ctLess returns 1 if a < b, and 0 otherwise, in constant time.
+func ctLess(a, b uint32) uint32 {
+	return uint32((uint64(a) - uint64(b)) >> 63)
+}
+
+// Reduce sets s = x mod l, where x is a 64-byte little-endian integer (as
+// produced by a wide hash output), and returns s.
+func Reduce(s *Scalar, x *[64]byte) *Scalar {
+	var wide [8]uint64
+	for i := range wide {
+		wide[i] = binary.LittleEndian.Uint64(x[i*8:])
+	}
+	*s = barrettReduce(wide)
+	return s
+}
+
+// Add sets s = a + b mod l and returns s.
+func Add(s, a, b *Scalar) *Scalar {
+	aL, bL := bytesToLimbs((*[32]byte)(a)), bytesToLimbs((*[32]byte)(b))
+	sum, _ := addLimbs(aL, bL)
+	if cmpGE(sum, lLimbs) {
+		sum, _ = subLimbs(sum, lLimbs)
+	}
+	*s = Scalar(limbsToBytes(sum))
+	return s
+}
+
+// Subtract sets s = a - b mod l and returns s.
+func Subtract(s, a, b *Scalar) *Scalar {
+	aL, bL := bytesToLimbs((*[32]byte)(a)), bytesToLimbs((*[32]byte)(b))
+	diff, borrow := subLimbs(aL, bL)
+	if borrow != 0 {
+		diff, _ = addLimbs(diff, lLimbs)
+	}
+	*s = Scalar(limbsToBytes(diff))
+	return s
+}
+
+// Negate sets s = -a mod l and returns s.
+func Negate(s, a *Scalar) *Scalar {
+	var zero Scalar
+	return Subtract(s, &zero, a)
+}
+
+// Multiply sets s = a * b mod l and returns s.
+func Multiply(s, a, b *Scalar) *Scalar {
+	aL, bL := bytesToLimbs((*[32]byte)(a)), bytesToLimbs((*[32]byte)(b))
+	*s = barrettReduce(mulLimbs(aL, bL))
+	return s
+}
+
+// MulAdd sets s = a*b + c mod l and returns s.
+func MulAdd(s, a, b, c *Scalar) *Scalar {
+	var t Scalar
+	Multiply(&t, a, b)
+	return Add(s, &t, c)
+}
+
+// Equal returns 1 if s and t are equal, and 0 otherwise.
+func Equal(s, t *Scalar) int {
+	return subtle.ConstantTimeCompare(s[:], t[:])
+}
+
+// Invert sets s = 1/a mod l and returns s, using Fermat's little theorem
+// (s = a^(l-2) mod l) via a fixed-length square-and-multiply chain. a must
+// be non-zero mod l.
+func Invert(s, a *Scalar) *Scalar {
+	var two, exp Scalar
+	two.One()
+	Add(&two, &two, &two)
+	Subtract(&exp, &l, &two)
+
+	result := new(Scalar).One()
+	base := *a
+	for i := 0; i < 253; i++ {
+		byteIdx, bitIdx := i/8, uint(i%8)
+		if (exp[byteIdx]>>bitIdx)&1 == 1 {
+			Multiply(result, result, &base)
+		}
+		Multiply(&base, &base, &base)
+	}
+	*s = *result
+	return s
+}
+
+func bytesToLimbs(b *[32]byte) [4]uint64 {
+	var limbs [4]uint64
+	for i := range limbs {
+		limbs[i] = binary.LittleEndian.Uint64(b[i*8:])
+	}
+	return limbs
+}
+
+func limbsToBytes(limbs [4]uint64) [32]byte {
+	var b [32]byte
+	for i, limb := range limbs {
+		binary.LittleEndian.PutUint64(b[i*8:], limb)
+	}
+	return b
+}
+
+func cmpGE(a, b [4]uint64) bool {
+	for i := 3; i >= 0; i-- {
+		switch {
+		case a[i] > b[i]:
+			return true
+		case a[i] < b[i]:
+			return false
+		}
+	}
+	return true // equal
+}
+
+func addLimbs(a, b [4]uint64) ([4]uint64, uint64) {
+	var sum [4]uint64
+	var carry uint64
+	for i := range sum {
+		sum[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	return sum, carry
+}
+
+func subLimbs(a, b [4]uint64) ([4]uint64, uint64) {
+	var diff [4]uint64
+	var borrow uint64
+	for i := range diff {
+		diff[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	return diff, borrow
+}
+
+// mulLimbs computes the full 512-bit schoolbook product of a and b.
+func mulLimbs(a, b [4]uint64) [8]uint64 {
+	var r [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c0, c1 uint64
+			lo, c0 = bits.Add64(lo, r[i+j], 0)
+			lo, c1 = bits.Add64(lo, carry, 0)
+			hi, _ = bits.Add64(hi, c0, c1)
+			r[i+j] = lo
+			carry = hi
+		}
+		for k := i + 4; carry != 0; k++ {
+			r[k], carry = bits.Add64(r[k], carry, 0)
+		}
+	}
+	return r
+}
+
+// barrettReduce reduces the 512-bit little-endian integer x modulo l using
+// Barrett reduction (HAC §14.3.3), replacing what was previously a 512-round
+// bit-serial long division with a handful of 256x256-bit multiplications.
+//
+// With b = 2^64 and l occupying k = 4 limbs, mu = floor(b^2k / l) is the
+// precomputed Barrett constant above. The two final conditional
+// subtractions are sufficient because this algorithm bounds the
+// intermediate remainder to below 3*l (HAC Note 14.44).
+func barrettReduce(x [8]uint64) Scalar {
+	q1 := [5]uint64{x[3], x[4], x[5], x[6], x[7]} // x >> 192
+	q2 := mul5(q1, mu)
+	q3 := [5]uint64{q2[5], q2[6], q2[7], q2[8], q2[9]} // q2 >> 320
+
+	r1 := [5]uint64{x[0], x[1], x[2], x[3], x[4]} // x mod 2^320
+	q3l := mul5(q3, lLimbs5)
+	r2 := [5]uint64{q3l[0], q3l[1], q3l[2], q3l[3], q3l[4]} // (q3 * l) mod 2^320
+
+	r := sub5(r1, r2) // wraps mod 2^320, equivalent to adding back 2^320 on borrow
+
+	for i := 0; i < 2; i++ {
+		if cmpGE5(r, lLimbs5) {
+			r = sub5(r, lLimbs5)
+		}
+	}
+
+	return Scalar(limbsToBytes([4]uint64{r[0], r[1], r[2], r[3]}))
+}
+
+// mul5 computes the full 640-bit schoolbook product of two 320-bit numbers.
+func mul5(a, b [5]uint64) [10]uint64 {
+	var r [10]uint64
+	for i := 0; i < 5; i++ {
+		var carry uint64
+		for j := 0; j < 5; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c0, c1 uint64
+			lo, c0 = bits.Add64(lo, r[i+j], 0)
+			lo, c1 = bits.Add64(lo, carry, 0)
+			hi, _ = bits.Add64(hi, c0, c1)
+			r[i+j] = lo
+			carry = hi
+		}
+		for k := i + 5; carry != 0; k++ {
+			r[k], carry = bits.Add64(r[k], carry, 0)
+		}
+	}
+	return r
+}
+
+// sub5 returns a - b mod 2^320.
+func sub5(a, b [5]uint64) [5]uint64 {
+	var diff [5]uint64
+	var borrow uint64
+	for i := range diff {
+		diff[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	return diff
+}
+
+// cmpGE5 reports whether a >= b, for 320-bit a and b.
+func cmpGE5(a, b [5]uint64) bool {
+	for i := 4; i >= 0; i-- {
+		switch {
+		case a[i] > b[i]:
+			return true
+		case a[i] < b[i]:
+			return false
+		}
+	}
+	return true // equal
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scalar
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// lBig is l, the group order, as a math/big value to check this package's
+// fixed-width arithmetic against.
+var lBig, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+func bigFromScalar(s *Scalar) *big.Int {
+	b := s.Bytes()
+	return new(big.Int).SetBytes(reverse(b[:]))
+}
+
+func scalarFromBig(t *testing.T, x *big.Int) *Scalar {
+	t.Helper()
+	var buf [32]byte
+	x = new(big.Int).Mod(x, lBig)
+	copy(buf[:], reverse(x.FillBytes(make([]byte, 32))))
+	s, ok := SetCanonicalBytes(new(Scalar), buf)
+	if !ok {
+		t.Fatalf("scalarFromBig: %v did not round-trip as canonical", x)
+	}
+	return s
+}
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+	return out
+}
+
+func randomBig(rnd *rand.Rand) *big.Int {
+	buf := make([]byte, 40) // a bit of slop above l's 252 bits, reduced below
+	rnd.Read(buf)
+	return new(big.Int).SetBytes(buf)
+}
+
+func TestAddSubtractMultiplyAgainstBigInt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		aBig, bBig := randomBig(rnd), randomBig(rnd)
+		a, b := scalarFromBig(t, aBig), scalarFromBig(t, bBig)
+
+		var sum, diff, prod Scalar
+		Add(&sum, a, b)
+		Subtract(&diff, a, b)
+		Multiply(&prod, a, b)
+
+		gotAdd := bigFromScalar(&sum)
+		wantAdd := new(big.Int).Mod(new(big.Int).Add(aBig, bBig), lBig)
+		if gotAdd.Cmp(wantAdd) != 0 {
+			t.Fatalf("Add(%v, %v) = %v, want %v", aBig, bBig, gotAdd, wantAdd)
+		}
+
+		gotSub := bigFromScalar(&diff)
+		wantSub := new(big.Int).Mod(new(big.Int).Sub(aBig, bBig), lBig)
+		if gotSub.Cmp(wantSub) != 0 {
+			t.Fatalf("Subtract(%v, %v) = %v, want %v", aBig, bBig, gotSub, wantSub)
+		}
+
+		gotMul := bigFromScalar(&prod)
+		wantMul := new(big.Int).Mod(new(big.Int).Mul(aBig, bBig), lBig)
+		if gotMul.Cmp(wantMul) != 0 {
+			t.Fatalf("Multiply(%v, %v) = %v, want %v", aBig, bBig, gotMul, wantMul)
+		}
+	}
+}
+
+func TestInvertAgainstBigInt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		aBig := randomBig(rnd)
+		aBig.Mod(aBig, lBig)
+		if aBig.Sign() == 0 {
+			aBig.SetInt64(1)
+		}
+		a := scalarFromBig(t, aBig)
+
+		var inv Scalar
+		Invert(&inv, a)
+		gotInv := bigFromScalar(&inv)
+		wantInv := new(big.Int).ModInverse(aBig, lBig)
+		if gotInv.Cmp(wantInv) != 0 {
+			t.Fatalf("Invert(%v) = %v, want %v", aBig, gotInv, wantInv)
+		}
+
+		var one Scalar
+		Multiply(&one, a, &inv)
+		if bigFromScalar(&one).Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("a * Invert(a) = %v, want 1 (a = %v)", bigFromScalar(&one), aBig)
+		}
+	}
+}
+
+func TestReduceAgainstBigInt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 2000; i++ {
+		var wide [64]byte
+		rnd.Read(wide[:])
+
+		var s Scalar
+		Reduce(&s, &wide)
+
+		want := new(big.Int).Mod(new(big.Int).SetBytes(reverse(wide[:])), lBig)
+		if got := bigFromScalar(&s); got.Cmp(want) != 0 {
+			t.Fatalf("Reduce(%x) = %v, want %v", wide, got, want)
+		}
+	}
+
+	// Edge cases: all-zero and all-0xff input.
+	var zero, max [64]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	var sZero, sMax Scalar
+	Reduce(&sZero, &zero)
+	if bigFromScalar(&sZero).Sign() != 0 {
+		t.Fatalf("Reduce(0) = %v, want 0", bigFromScalar(&sZero))
+	}
+	Reduce(&sMax, &max)
+	want := new(big.Int).Mod(new(big.Int).SetBytes(reverse(max[:])), lBig)
+	if got := bigFromScalar(&sMax); got.Cmp(want) != 0 {
+		t.Fatalf("Reduce(2^512-1) = %v, want %v", got, want)
+	}
+}
+
+func TestIsReducedBoundary(t *testing.T) {
+	var lMinusOne, lExact, lPlusOne, zero [32]byte
+	copy(lMinusOne[:], l[:])
+	lMinusOne[0]--
+	copy(lExact[:], l[:])
+	copy(lPlusOne[:], l[:])
+	lPlusOne[0]++
+
+	cases := []struct {
+		name string
+		x    [32]byte
+		want bool
+	}{
+		{"zero", zero, true},
+		{"l-1", lMinusOne, true},
+		{"l", lExact, false},
+		{"l+1", lPlusOne, false},
+	}
+	for _, c := range cases {
+		if _, ok := SetCanonicalBytes(new(Scalar), c.x); ok != c.want {
+			t.Errorf("isReduced(%s) = %v, want %v", c.name, ok, c.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"math/big"
+
+	"github.com/gtank/ristretto255/internal/group"
+)
+
+// Window widths used for the wNAF expansions below: one for arbitrary
+// points, and a wider one for the basepoint. The basepoint's table of odd
+// multiples is built afresh on every call, like every other term's.
+const (
+	multiScalarWindow          = 5
+	multiScalarBasepointWindow = 6
+
+	// pippengerThreshold is the number of points above which the bucket
+	// method starts winning over Straus's interleaved wNAF.
+	pippengerThreshold = 190
+)
+
+// VartimeMultiScalarMult returns a new Element set to
+// sum_i scalars[i]*points[i]. scalars and points must have the same length.
+//
+// Execution time depends on the scalars and points, and is therefore NOT
+// safe to use with secret data. It is intended for batch verification of
+// signatures and zero-knowledge proofs, where speed matters more than
+// constant time and none of the inputs are secret.
+func VartimeMultiScalarMult(scalars []*Scalar, points []*Element) *Element {
+	if len(scalars) != len(points) {
+		panic("ristretto255: VartimeMultiScalarMult: scalars and points have different lengths")
+	}
+	return vartimeMultiScalarMult(nil, scalars, points)
+}
+
+// VartimeMultiScalarMultBasepoint returns a new Element set to
+//
+//	basepointScalar*B + sum_i scalars[i]*points[i]
+//
+// where B is the ristretto255 basepoint. It is useful for
+// verifiers that always have the basepoint as one of the terms, such as
+// Schnorr signature verifiers. See VartimeMultiScalarMult for the
+// variable-time caveat.
+func VartimeMultiScalarMultBasepoint(basepointScalar *Scalar, scalars []*Scalar, points []*Element) *Element {
+	if len(scalars) != len(points) {
+		panic("ristretto255: VartimeMultiScalarMultBasepoint: scalars and points have different lengths")
+	}
+	return vartimeMultiScalarMult(basepointScalar, scalars, points)
+}
+
+// wnafTerm holds one summand of a multi-scalar multiplication: the wNAF
+// digit expansion of its scalar, the window width that expansion was
+// computed at, and a table of its odd multiples {P, 3P, 5P, ...,
+// (2^(w-1)-1)P}.
+type wnafTerm struct {
+	digits []int32
+	window uint
+	odd    []group.ExtendedGroupElement
+}
+
+func newWnafTerm(s *Scalar, p *Element, w uint) wnafTerm {
+	odd := oddMultiples(&p.r, w)
+	return wnafTerm{digits: wnaf(s, w), window: w, odd: odd}
+}
+
+// oddMultiples returns {P, 3P, 5P, ..., (2^(w-1)-1)P}.
+func oddMultiples(p *group.ExtendedGroupElement, w uint) []group.ExtendedGroupElement {
+	count := 1 << (w - 2)
+	odd := make([]group.ExtendedGroupElement, count)
+	odd[0] = *p
+
+	var twiceP group.ExtendedGroupElement
+	twiceP.Add(p, p)
+	for i := 1; i < count; i++ {
+		odd[i].Add(&odd[i-1], &twiceP)
+	}
+	return odd
+}
+
+// wnaf computes the width-w non-adjacent form of s: a little-endian digit
+// expansion in which every digit is 0 or odd and in [-(2^(w-1)-1),
+// 2^(w-1)-1], and no two consecutive digits are both nonzero.
+func wnaf(s *Scalar, w uint) []int32 {
+	b := s.Bytes()
+	be := make([]byte, len(b))
+	for i, v := range b {
+		be[len(b)-1-i] = v
+	}
+	x := new(big.Int).SetBytes(be)
+
+	width := int64(1) << w
+	half := int64(1) << (w - 1)
+
+	var digits []int32
+	for x.Sign() != 0 {
+		var digit int64
+		if x.Bit(0) == 1 {
+			digit = new(big.Int).And(x, big.NewInt(width-1)).Int64()
+			if digit >= half {
+				digit -= width
+			}
+			x.Sub(x, big.NewInt(digit))
+		}
+		digits = append(digits, int32(digit))
+		x.Rsh(x, 1)
+	}
+	return digits
+}
+
+// addDigit adds t's contribution at wNAF digit position i to acc (a no-op
+// if the digit is zero or position i is out of range).
+func (t *wnafTerm) addDigit(acc *group.ExtendedGroupElement, i int) {
+	if i >= len(t.digits) {
+		return
+	}
+	d := t.digits[i]
+	if d == 0 {
+		return
+	}
+
+	idx := d
+	neg := idx < 0
+	if neg {
+		idx = -idx
+	}
+	p := t.odd[(idx-1)/2]
+	if neg {
+		negatePoint(&p, &p)
+	}
+	acc.Add(acc, &p)
+}
+
+func vartimeMultiScalarMult(basepointScalar *Scalar, scalars []*Scalar, points []*Element) *Element {
+	terms := make([]wnafTerm, 0, len(points)+1)
+	if basepointScalar != nil {
+		terms = append(terms, newWnafTerm(basepointScalar, &Element{r: group.B}, multiScalarBasepointWindow))
+	}
+	for i, p := range points {
+		terms = append(terms, newWnafTerm(scalars[i], p, multiScalarWindow))
+	}
+
+	if len(points) > pippengerThreshold {
+		return pippengerMultiScalarMult(terms)
+	}
+	return strausMultiScalarMult(terms)
+}
+
+// strausMultiScalarMult evaluates a multi-scalar multiplication by scanning
+// all wNAF expansions together from the most to the least significant
+// digit, sharing one doubling per position across every term.
+func strausMultiScalarMult(terms []wnafTerm) *Element {
+	maxLen := 0
+	for _, t := range terms {
+		if len(t.digits) > maxLen {
+			maxLen = len(t.digits)
+		}
+	}
+
+	acc := identityElement()
+	for i := maxLen - 1; i >= 0; i-- {
+		acc.Add(&acc, &acc)
+		for j := range terms {
+			terms[j].addDigit(&acc, i)
+		}
+	}
+
+	return &Element{r: acc}
+}
+
+// pippengerMultiScalarMult evaluates a multi-scalar multiplication with a
+// bucket method: each digit position is split into buckets by the digit's
+// raw (unscaled) magnitude, the buckets are summed once with a
+// running-sum trick that multiplies each bucket by its index as a side
+// effect, and the per-position partial sums are combined with one
+// doubling each. This overtakes Straus's method once there are enough
+// points that sharing bucket additions beats sharing doublings.
+//
+// Unlike strausMultiScalarMult, this does not read the precomputed odd
+// multiples beyond t.odd[0] (the term's raw point): the running-sum
+// trick below already multiplies a bucketed point by its digit's
+// magnitude, so bucketing an already-scaled odd multiple would scale it
+// twice.
+func pippengerMultiScalarMult(terms []wnafTerm) *Element {
+	maxLen, maxWindow := 0, uint(multiScalarWindow)
+	for _, t := range terms {
+		if len(t.digits) > maxLen {
+			maxLen = len(t.digits)
+		}
+		if t.window > maxWindow {
+			maxWindow = t.window
+		}
+	}
+
+	numBuckets := 1 << (maxWindow - 1)
+
+	acc := identityElement()
+	for i := maxLen - 1; i >= 0; i-- {
+		acc.Add(&acc, &acc)
+
+		buckets := make([]group.ExtendedGroupElement, numBuckets+1)
+		for k := range buckets {
+			buckets[k] = identityElement()
+		}
+
+		for _, t := range terms {
+			if i >= len(t.digits) {
+				continue
+			}
+			d := t.digits[i]
+			if d == 0 {
+				continue
+			}
+			idx := d
+			neg := idx < 0
+			if neg {
+				idx = -idx
+			}
+			p := t.odd[0]
+			if neg {
+				negatePoint(&p, &p)
+			}
+			buckets[idx].Add(&buckets[idx], &p)
+		}
+
+		// Running sum: sum_k k*buckets[k] = sum of partial sums of
+		// buckets from the top down.
+		running := identityElement()
+		partial := identityElement()
+		for k := numBuckets; k >= 1; k-- {
+			running.Add(&running, &buckets[k])
+			partial.Add(&partial, &running)
+		}
+		acc.Add(&acc, &partial)
+	}
+
+	return &Element{r: acc}
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomScalarForTest(rnd *rand.Rand) *Scalar {
+	var b [64]byte
+	rnd.Read(b[:])
+	return new(Scalar).FromUniformBytes(b[:])
+}
+
+func randomElementForTest(rnd *rand.Rand) *Element {
+	var b [64]byte
+	rnd.Read(b[:])
+	e := new(Element)
+	e.FromUniformBytes(b[:])
+	return e
+}
+
+// naiveMultiScalarMult computes sum_i scalars[i]*points[i] with a loop of
+// independent ScalarMult and Add calls, as a reference for
+// VartimeMultiScalarMult that shares no code with either the Straus or the
+// Pippenger path.
+func naiveMultiScalarMult(scalars []*Scalar, points []*Element) *Element {
+	acc := new(Element).Zero()
+	for i := range scalars {
+		acc.Add(acc, new(Element).ScalarMult(scalars[i], points[i]))
+	}
+	return acc
+}
+
+// TestVartimeMultiScalarMultAgainstNaive exercises both the Straus path
+// (len(points) <= pippengerThreshold) and the Pippenger path, including
+// sizes right at the threshold: a stride bug in the Pippenger running sum
+// once collapsed every result on that path to the identity element without
+// any panic or visible error.
+func TestVartimeMultiScalarMultAgainstNaive(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	sizes := []int{0, 1, 2, 5, 32, pippengerThreshold, pippengerThreshold + 1, 250, 400}
+
+	for _, n := range sizes {
+		scalars := make([]*Scalar, n)
+		points := make([]*Element, n)
+		for i := range scalars {
+			scalars[i] = randomScalarForTest(rnd)
+			points[i] = randomElementForTest(rnd)
+		}
+
+		got := VartimeMultiScalarMult(scalars, points)
+		want := naiveMultiScalarMult(scalars, points)
+		if got.Equal(want) != 1 {
+			t.Fatalf("VartimeMultiScalarMult with %d terms disagreed with naive reference", n)
+		}
+	}
+}
+
+func TestVartimeMultiScalarMultBasepointAgainstNaive(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+
+	var oneBytes [32]byte
+	oneBytes[0] = 1
+	one, err := new(Scalar).FromCanonicalBytes(oneBytes[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	B := new(Element).ScalarBaseMult(one)
+
+	sizes := []int{0, 1, 3, pippengerThreshold + 10}
+	for _, n := range sizes {
+		basepointScalar := randomScalarForTest(rnd)
+		scalars := make([]*Scalar, n)
+		points := make([]*Element, n)
+		for i := range scalars {
+			scalars[i] = randomScalarForTest(rnd)
+			points[i] = randomElementForTest(rnd)
+		}
+
+		got := VartimeMultiScalarMultBasepoint(basepointScalar, scalars, points)
+
+		want := new(Element).ScalarMult(basepointScalar, B)
+		want.Add(want, naiveMultiScalarMult(scalars, points))
+
+		if got.Equal(want) != 1 {
+			t.Fatalf("VartimeMultiScalarMultBasepoint with %d terms disagreed with naive reference", n)
+		}
+	}
+}
@@ -8,10 +8,25 @@
 package ristretto255
 
 import (
+	"crypto/subtle"
+	"errors"
+
 	"github.com/gtank/ristretto255/internal/group"
 	"github.com/gtank/ristretto255/internal/radix51"
 )
 
+// fieldElementFromDecimal parses a base-10 constant into a FieldElement.
+func fieldElementFromDecimal(s string) *radix51.FieldElement {
+	return radix51.FeFromDecimal(s)
+}
+
+// feSqrtRatio sets out to a square root of u/v (or, if u/v is not a
+// square, to a related value) and returns 1 if u/v was square, 0
+// otherwise. See radix51.FeSqrtRatio.
+func feSqrtRatio(out, u, v *radix51.FieldElement) int {
+	return radix51.FeSqrtRatio(out, u, v)
+}
+
 var (
 	sqrtM1 = fieldElementFromDecimal(
 		"19681161376707505956807079304988542015446066515923890162744021073123829784752")
@@ -46,6 +61,122 @@ func (e *Element) Equal(ee *Element) int {
 	return out
 }
 
+// Encode appends the 32-byte canonical encoding of e to b and returns the
+// result. This is the encoding defined in draft-hdevalence-cfrg-ristretto-00
+// §3.2.2, and it is constant-time.
+func (e *Element) Encode(b []byte) []byte {
+	var u1, u2, i1, i2, zInv, invSqrt, ix, iy, enchantedDenom, denInv, x, y, s, tmp radix51.FieldElement
+	one := &radix51.FieldElement{}
+	radix51.FeOne(one)
+
+	// u1 = (Z + Y) * (Z - Y), u2 = X * Y
+	radix51.FeAdd(&tmp, &e.r.Z, &e.r.Y)
+	radix51.FeSub(&u1, &e.r.Z, &e.r.Y)
+	radix51.FeMul(&u1, &u1, &tmp)
+	radix51.FeMul(&u2, &e.r.X, &e.r.Y)
+
+	// invSqrt = 1 / sqrt(u1 * u2^2)
+	radix51.FeSquare(&tmp, &u2)
+	radix51.FeMul(&tmp, &tmp, &u1)
+	feSqrtRatio(&invSqrt, one, &tmp)
+
+	radix51.FeMul(&i1, &invSqrt, &u1)
+	radix51.FeMul(&i2, &invSqrt, &u2)
+
+	// zInv = i1 * i2 * T
+	radix51.FeMul(&zInv, &i2, &e.r.T)
+	radix51.FeMul(&zInv, &zInv, &i1)
+
+	radix51.FeMul(&ix, &e.r.X, sqrtM1)
+	radix51.FeMul(&iy, &e.r.Y, sqrtM1)
+	radix51.FeMul(&enchantedDenom, &i1, invSqrtAMinusD)
+
+	radix51.FeMul(&tmp, &e.r.T, &zInv)
+	rotate := radix51.FeIsNegative(&tmp)
+
+	radix51.FeSelect(&x, &iy, &e.r.X, rotate)
+	radix51.FeSelect(&y, &ix, &e.r.Y, rotate)
+	radix51.FeSelect(&denInv, &enchantedDenom, &i2, rotate)
+
+	radix51.FeMul(&tmp, &x, &zInv)
+	radix51.FeCondNeg(&y, radix51.FeIsNegative(&tmp))
+
+	radix51.FeSub(&tmp, &e.r.Z, &y)
+	radix51.FeMul(&s, &denInv, &tmp)
+	radix51.FeCondNeg(&s, radix51.FeIsNegative(&s))
+
+	var out [32]byte
+	radix51.FeToBytes(&out, &s)
+	return append(b, out[:]...)
+}
+
+// Decode sets e to the decoded value of the 32-byte canonical encoding in
+// src and returns nil, or returns an error if src is not the canonical
+// encoding of an Element. This operation is constant-time.
+func (e *Element) Decode(src []byte) error {
+	if len(src) != 32 {
+		return errors.New("ristretto255: invalid Element encoding")
+	}
+
+	var in [32]byte
+	copy(in[:], src)
+
+	var s radix51.FieldElement
+	radix51.FeFromBytes(&s, &in)
+
+	// Reject non-canonical field encodings and negative s.
+	var check [32]byte
+	radix51.FeToBytes(&check, &s)
+	if subtle.ConstantTimeCompare(check[:], in[:]) == 0 {
+		return errors.New("ristretto255: invalid Element encoding")
+	}
+	if radix51.FeIsNegative(&s) == 1 {
+		return errors.New("ristretto255: invalid Element encoding")
+	}
+
+	one := &radix51.FieldElement{}
+	radix51.FeOne(one)
+
+	var ss, u1, u2, u2Sq, v, invSqrt, tmp radix51.FieldElement
+	radix51.FeSquare(&ss, &s)
+	radix51.FeSub(&u1, one, &ss)
+	radix51.FeAdd(&u2, one, &ss)
+	radix51.FeSquare(&u2Sq, &u2)
+
+	radix51.FeSquare(&tmp, &u1)
+	radix51.FeMul(&v, &group.D, &tmp)
+	radix51.FeNeg(&v, &v)
+	radix51.FeSub(&v, &v, &u2Sq)
+
+	radix51.FeMul(&tmp, &v, &u2Sq)
+	wasSquare := feSqrtRatio(&invSqrt, one, &tmp)
+
+	var dx, dy radix51.FieldElement
+	radix51.FeMul(&dx, &invSqrt, &u2)
+	radix51.FeMul(&dy, &dx, &v)
+	radix51.FeMul(&dy, &dy, &invSqrt)
+
+	var x, y, t radix51.FieldElement
+	radix51.FeAdd(&x, &s, &s)
+	radix51.FeMul(&x, &x, &dx)
+	radix51.FeAbs(&x, &x)
+
+	radix51.FeMul(&y, &u1, &dy)
+	radix51.FeMul(&t, &x, &y)
+
+	var zero radix51.FieldElement
+	if wasSquare == 0 || radix51.FeIsNegative(&t) == 1 || radix51.FeEqual(&y, &zero) == 1 {
+		return errors.New("ristretto255: invalid Element encoding")
+	}
+
+	e.r.X = x
+	e.r.Y = y
+	radix51.FeOne(&e.r.Z)
+	e.r.T = t
+
+	return nil
+}
+
 // FromUniformBytes maps the 64-byte slice b to an Element e uniformly and
 // deterministically. This can be used for hash-to-group operations or to obtain
 // a random element.
@@ -125,4 +256,4 @@ func mapToPoint(out *group.ExtendedGroupElement, t *radix51.FieldElement) {
 	radix51.FeMul(&out.Y, w2, w1)
 	radix51.FeMul(&out.Z, w1, w3)
 	radix51.FeMul(&out.T, w0, w2)
-}
\ No newline at end of file
+}
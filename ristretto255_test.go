@@ -0,0 +1,83 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// TestEncodeDecodeRoundTrip checks that decoding the canonical encoding of a
+// random Element returns an equal Element, for both the identity and
+// uniformly sampled points.
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	elements := []*Element{new(Element).Zero()}
+	for i := 0; i < 200; i++ {
+		elements = append(elements, randomElementForTest(rnd))
+	}
+
+	for i, e := range elements {
+		enc := e.Encode(nil)
+		if len(enc) != 32 {
+			t.Fatalf("element %d: Encode returned %d bytes, want 32", i, len(enc))
+		}
+
+		var got Element
+		if err := got.Decode(enc); err != nil {
+			t.Fatalf("element %d: Decode(Encode(e)) failed: %v", i, err)
+		}
+		if got.Equal(e) != 1 {
+			t.Fatalf("element %d: Decode(Encode(e)) != e", i)
+		}
+
+		// The canonical encoding is unique, so re-encoding the decoded
+		// value must reproduce the same bytes.
+		if reenc := got.Encode(nil); !bytes.Equal(reenc, enc) {
+			t.Fatalf("element %d: re-encoding after decode changed the bytes", i)
+		}
+	}
+}
+
+// TestDecodeRejectsMalformedInput checks that Decode rejects inputs that
+// are not the canonical encoding of a ristretto255 element: wrong length,
+// a non-canonical field element encoding, and a negative s value.
+func TestDecodeRejectsMalformedInput(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []byte
+	}{
+		{"too short", make([]byte, 31)},
+		{"too long", make([]byte, 33)},
+		{"empty", nil},
+		// p = 2^255 - 19, the field modulus: the encoded value is not
+		// below p, so this is a non-canonical encoding of the field
+		// element 0.
+		{"non-canonical field element (p)", []byte{
+			0xed, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+		}},
+		// s = 1: a canonical field element encoding, but odd, so it is
+		// the negative representative under the sign convention
+		// ristretto255 uses and must be rejected.
+		{"negative s", []byte{
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		}},
+	}
+
+	for _, c := range cases {
+		if err := new(Element).Decode(c.in); err == nil {
+			t.Errorf("Decode(%s) succeeded, want an error", c.name)
+		}
+	}
+}
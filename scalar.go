@@ -0,0 +1,247 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ristretto255
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sync"
+
+	"github.com/gtank/ristretto255/internal/group"
+	"github.com/gtank/ristretto255/internal/radix51"
+	"github.com/gtank/ristretto255/internal/scalar"
+)
+
+// Scalar is an integer modulo
+//
+//	l = 2^252 + 27742317777372353535851937790883648493
+//
+// which is the order of the ristretto255 group.
+//
+// This type works similarly to math/big.Int, and all arguments and
+// receivers are allowed to alias.
+type Scalar struct {
+	s scalar.Scalar
+}
+
+// NewScalar returns a Scalar set to 0.
+func NewScalar() *Scalar {
+	return &Scalar{}
+}
+
+// FromUniformBytes sets s to an uniformly distributed value given 64
+// uniformly distributed random bytes, and returns s.
+func (s *Scalar) FromUniformBytes(b []byte) *Scalar {
+	if len(b) != 64 {
+		panic("ristretto255: invalid FromUniformBytes input length")
+	}
+	var wide [64]byte
+	copy(wide[:], b)
+	scalar.Reduce(&s.s, &wide)
+	return s
+}
+
+// FromCanonicalBytes sets s = x, where x is a 32-byte little-endian
+// encoding of s, and returns s. If x is not a canonical encoding of s,
+// FromCanonicalBytes returns an error and the receiver is unmodified.
+func (s *Scalar) FromCanonicalBytes(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("ristretto255: invalid Scalar encoding")
+	}
+	var in [32]byte
+	copy(in[:], x)
+	if _, ok := scalar.SetCanonicalBytes(&s.s, in); !ok {
+		return nil, errors.New("ristretto255: invalid Scalar encoding")
+	}
+	return s, nil
+}
+
+// Bytes returns the canonical 32-byte little-endian encoding of s.
+func (s *Scalar) Bytes() []byte {
+	b := s.s.Bytes()
+	return b[:]
+}
+
+// Add sets s = x + y mod l and returns s.
+func (s *Scalar) Add(x, y *Scalar) *Scalar {
+	scalar.Add(&s.s, &x.s, &y.s)
+	return s
+}
+
+// Subtract sets s = x - y mod l and returns s.
+func (s *Scalar) Subtract(x, y *Scalar) *Scalar {
+	scalar.Subtract(&s.s, &x.s, &y.s)
+	return s
+}
+
+// Negate sets s = -x mod l and returns s.
+func (s *Scalar) Negate(x *Scalar) *Scalar {
+	scalar.Negate(&s.s, &x.s)
+	return s
+}
+
+// Multiply sets s = x * y mod l and returns s.
+func (s *Scalar) Multiply(x, y *Scalar) *Scalar {
+	scalar.Multiply(&s.s, &x.s, &y.s)
+	return s
+}
+
+// Invert sets s = 1/x mod l and returns s. x must be non-zero.
+func (s *Scalar) Invert(x *Scalar) *Scalar {
+	scalar.Invert(&s.s, &x.s)
+	return s
+}
+
+// Equal returns 1 if s and t are equal, and 0 otherwise.
+func (s *Scalar) Equal(t *Scalar) int {
+	return scalar.Equal(&s.s, &t.s)
+}
+
+const (
+	scalarMultWindowBits = 4
+	scalarMultTableSize  = 1 << scalarMultWindowBits
+)
+
+// identityElement returns the identity of the ristretto255 group in
+// extended coordinates: (X:Y:Z:T) = (0:1:1:0).
+func identityElement() group.ExtendedGroupElement {
+	var p group.ExtendedGroupElement
+	radix51.FeOne(&p.Y)
+	radix51.FeOne(&p.Z)
+	return p
+}
+
+// selectPoint sets out to a if cond == 1, and to b if cond == 0.
+func selectPoint(out, a, b *group.ExtendedGroupElement, cond int) {
+	radix51.FeSelect(&out.X, &a.X, &b.X, cond)
+	radix51.FeSelect(&out.Y, &a.Y, &b.Y, cond)
+	radix51.FeSelect(&out.Z, &a.Z, &b.Z, cond)
+	radix51.FeSelect(&out.T, &a.T, &b.T, cond)
+}
+
+// negatePoint sets out = -p, where -[X:Y:Z:T] = [-X:Y:Z:-T].
+func negatePoint(out, p *group.ExtendedGroupElement) {
+	radix51.FeNeg(&out.X, &p.X)
+	out.Y = p.Y
+	out.Z = p.Z
+	radix51.FeNeg(&out.T, &p.T)
+}
+
+// Add sets e = p + q and returns e.
+func (e *Element) Add(p, q *Element) *Element {
+	e.r.Add(&p.r, &q.r)
+	return e
+}
+
+// Subtract sets e = p - q and returns e.
+func (e *Element) Subtract(p, q *Element) *Element {
+	var negQ group.ExtendedGroupElement
+	negatePoint(&negQ, &q.r)
+	e.r.Add(&p.r, &negQ)
+	return e
+}
+
+// Negate sets e = -p and returns e.
+func (e *Element) Negate(p *Element) *Element {
+	negatePoint(&e.r, &p.r)
+	return e
+}
+
+// Zero sets e to the identity element and returns e.
+func (e *Element) Zero() *Element {
+	e.r = identityElement()
+	return e
+}
+
+// ScalarMult sets e = s * p and returns e. This function executes in
+// constant time with respect to both s and p.
+func (e *Element) ScalarMult(s *Scalar, p *Element) *Element {
+	var table [scalarMultTableSize]group.ExtendedGroupElement
+	table[0] = identityElement()
+	table[1] = p.r
+	for i := 2; i < scalarMultTableSize; i++ {
+		table[i].Add(&table[i-1], &p.r)
+	}
+	return e.scalarMultTable(s, &table)
+}
+
+var (
+	basepointTableOnce sync.Once
+	basepointTable     [scalarMultTableSize]group.ExtendedGroupElement
+)
+
+func basepointWindowTable() *[scalarMultTableSize]group.ExtendedGroupElement {
+	basepointTableOnce.Do(func() {
+		basepointTable[0] = identityElement()
+		basepointTable[1] = group.B
+		for i := 2; i < scalarMultTableSize; i++ {
+			basepointTable[i].Add(&basepointTable[i-1], &group.B)
+		}
+	})
+	return &basepointTable
+}
+
+// ScalarBaseMult sets e = s * B, where B is the ristretto255 basepoint, and
+// returns e. Because the multiples of B are precomputed once and reused,
+// this is faster than ScalarMult(s, basepointElement). This function
+// executes in constant time with respect to s.
+func (e *Element) ScalarBaseMult(s *Scalar) *Element {
+	return e.scalarMultTable(s, basepointWindowTable())
+}
+
+// scalarMultTable multiplies the point whose multiples of 0..15 are stored
+// in table by s, using a constant-time fixed 4-bit window ladder: the
+// accumulator is doubled four times per digit of s and then the table
+// entry for that digit is added in via a constant-time table scan.
+func (e *Element) scalarMultTable(s *Scalar, table *[scalarMultTableSize]group.ExtendedGroupElement) *Element {
+	digits := windowedDigits(&s.s, scalarMultWindowBits)
+
+	acc := identityElement()
+	for i := len(digits) - 1; i >= 0; i-- {
+		for j := 0; j < scalarMultWindowBits; j++ {
+			acc.Add(&acc, &acc)
+		}
+
+		selected := identityElement()
+		for j := 1; j < scalarMultTableSize; j++ {
+			var tmp group.ExtendedGroupElement
+			selectPoint(&tmp, &table[j], &selected, ctEqual(digits[i], j))
+			selected = tmp
+		}
+
+		acc.Add(&acc, &selected)
+	}
+
+	e.r = acc
+	return e
+}
+
+// windowedDigits splits the little-endian encoding of s into unsigned
+// digits of windowBits bits each, least-significant digit first.
+func windowedDigits(s *scalar.Scalar, windowBits int) []int {
+	raw := s.Bytes()
+	mask := (1 << uint(windowBits)) - 1
+
+	numDigits := (8 * len(raw)) / windowBits
+	digits := make([]int, numDigits)
+	for i := range digits {
+		bitPos := i * windowBits
+		byteIdx := bitPos / 8
+		shift := uint(bitPos % 8)
+
+		v := int(raw[byteIdx]) >> shift
+		if shift+uint(windowBits) > 8 && byteIdx+1 < len(raw) {
+			v |= int(raw[byteIdx+1]) << (8 - shift)
+		}
+		digits[i] = v & mask
+	}
+	return digits
+}
+
+// ctEqual returns 1 if a == b, and 0 otherwise, in constant time.
+func ctEqual(a, b int) int {
+	return subtle.ConstantTimeEq(int32(a), int32(b))
+}
@@ -0,0 +1,297 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package voprf implements the ristretto255-SHA512 ciphersuite of RFC 9497,
+// in all three of its modes: a plain oblivious PRF, a verifiable OPRF that
+// lets the client check the server evaluated its committed key, and a
+// partially-oblivious VOPRF that additionally binds evaluation to public
+// info agreed on by both parties.
+package voprf
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	"github.com/gtank/ristretto255"
+)
+
+// Mode selects which of the three RFC 9497 protocol variants to run.
+type Mode byte
+
+const (
+	OPRF  Mode = 0x00
+	VOPRF Mode = 0x01
+	POPRF Mode = 0x02
+)
+
+const suiteID = "ristretto255-SHA512"
+
+func contextString(mode Mode) []byte {
+	return []byte("OPRFV1-" + string([]byte{byte(mode)}) + "-" + suiteID)
+}
+
+func dst(label string, mode Mode) []byte {
+	return append([]byte(label), contextString(mode)...)
+}
+
+// DeriveKeyPair deterministically derives a server key pair from seed and
+// an optional public info string, so tests and key backups don't need to
+// carry raw scalars around.
+func DeriveKeyPair(seed, info []byte) (sk *ristretto255.Scalar, pk *ristretto255.Element) {
+	h := sha512.New()
+	h.Write(seed)
+	writeLenPrefixed(h, info)
+	sk = ristretto255.HashToScalar(h.Sum(nil), dst("DeriveKeyPair", OPRF))
+	pk = new(ristretto255.Element).ScalarBaseMult(sk)
+	return sk, pk
+}
+
+// GenerateKeyPair returns a fresh, randomly generated server key pair.
+func GenerateKeyPair() (sk *ristretto255.Scalar, pk *ristretto255.Element, err error) {
+	var seed [32]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, nil, err
+	}
+	sk, pk = DeriveKeyPair(seed[:], nil)
+	return sk, pk, nil
+}
+
+// Blind maps input to the group and blinds it with a fresh random scalar.
+// blind must be kept secret by the client and supplied again to Finalize;
+// blindedElement is sent to the server.
+func Blind(mode Mode, input []byte) (blind *ristretto255.Scalar, blindedElement *ristretto255.Element) {
+	P := ristretto255.HashToGroup(input, dst("HashToGroup", mode))
+	blind = randomScalar()
+	blindedElement = new(ristretto255.Element).ScalarMult(blind, P)
+	return blind, blindedElement
+}
+
+// Evaluate computes the server's response to a client's blindedElement
+// under sk. For VOPRF and POPRF it also returns a DLEQ proof that the
+// evaluation used the key corresponding to pk = sk*G; for OPRF, proof is
+// nil. info is only used, and required, in POPRF mode.
+func Evaluate(mode Mode, sk *ristretto255.Scalar, blindedElement *ristretto255.Element, info []byte) (evaluatedElement *ristretto255.Element, proof []byte) {
+	evaluatedElements, proof := EvaluateBatch(mode, sk, []*ristretto255.Element{blindedElement}, info)
+	return evaluatedElements[0], proof
+}
+
+// EvaluateBatch is the batch form of Evaluate: it evaluates every element
+// of blindedElements under sk and, for VOPRF and POPRF, returns a single
+// DLEQ proof covering the whole batch using the batched construction of
+// RFC 9497 §2.2 (one Fiat-Shamir challenge over a random linear combination
+// of all the pairs, computed by computeComposites).
+func EvaluateBatch(mode Mode, sk *ristretto255.Scalar, blindedElements []*ristretto255.Element, info []byte) (evaluatedElements []*ristretto255.Element, proof []byte) {
+	if mode == OPRF {
+		evaluatedElements = scalarMultEach(sk, blindedElements)
+		return evaluatedElements, nil
+	}
+
+	t := sk
+	if mode == POPRF {
+		m := infoScalar(mode, info)
+		t = new(ristretto255.Scalar).Add(sk, m)
+	}
+	tweakedKey := new(ristretto255.Element).ScalarBaseMult(t)
+
+	if mode == VOPRF {
+		evaluatedElements = scalarMultEach(t, blindedElements)
+		proof = generateProof(mode, t, tweakedKey, blindedElements, evaluatedElements)
+		return evaluatedElements, proof
+	}
+
+	// POPRF: evaluatedElements[i] = t^-1 * blindedElements[i], so
+	// blindedElements[i] = t*evaluatedElements[i] and the DLEQ runs with
+	// the pairs swapped.
+	tInv := new(ristretto255.Scalar).Invert(t)
+	evaluatedElements = scalarMultEach(tInv, blindedElements)
+	proof = generateProof(mode, t, tweakedKey, evaluatedElements, blindedElements)
+	return evaluatedElements, proof
+}
+
+// Finalize verifies (for VOPRF and POPRF) and unblinds the server's
+// response, returning the OPRF output for input. pk is the server's public
+// key and is ignored in OPRF mode.
+func Finalize(mode Mode, input []byte, blind *ristretto255.Scalar, evaluatedElement *ristretto255.Element, proof []byte, info []byte, pk *ristretto255.Element) ([]byte, error) {
+	outputs, err := FinalizeBatch(mode, [][]byte{input}, []*ristretto255.Scalar{blind}, []*ristretto255.Element{evaluatedElement}, proof, info, pk)
+	if err != nil {
+		return nil, err
+	}
+	return outputs[0], nil
+}
+
+// FinalizeBatch is the batch form of Finalize: it verifies the single proof
+// covering the batch (for VOPRF and POPRF) and unblinds every element,
+// returning one OPRF output per input. inputs, blinds and
+// evaluatedElements must have the same length.
+func FinalizeBatch(mode Mode, inputs [][]byte, blinds []*ristretto255.Scalar, evaluatedElements []*ristretto255.Element, proof []byte, info []byte, pk *ristretto255.Element) ([][]byte, error) {
+	if len(inputs) != len(blinds) || len(inputs) != len(evaluatedElements) {
+		return nil, errors.New("voprf: FinalizeBatch: inputs, blinds and evaluatedElements have different lengths")
+	}
+
+	blindedElements := make([]*ristretto255.Element, len(inputs))
+	for i, input := range inputs {
+		P := ristretto255.HashToGroup(input, dst("HashToGroup", mode))
+		blindedElements[i] = new(ristretto255.Element).ScalarMult(blinds[i], P)
+	}
+
+	if mode != OPRF {
+		verifyKey := pk
+		Bs, Cs := blindedElements, evaluatedElements
+		if mode == POPRF {
+			m := infoScalar(mode, info)
+			verifyKey = new(ristretto255.Element).Add(pk, new(ristretto255.Element).ScalarBaseMult(m))
+			Bs, Cs = evaluatedElements, blindedElements
+		}
+		if !verifyProof(mode, verifyKey, Bs, Cs, proof) {
+			return nil, errors.New("voprf: invalid proof")
+		}
+	}
+
+	outputs := make([][]byte, len(inputs))
+	for i, input := range inputs {
+		blindInv := new(ristretto255.Scalar).Invert(blinds[i])
+		N := new(ristretto255.Element).ScalarMult(blindInv, evaluatedElements[i])
+
+		h := sha512.New()
+		writeLenPrefixed(h, input)
+		if mode == POPRF {
+			writeLenPrefixed(h, info)
+		}
+		writeLenPrefixed(h, N.Encode(nil))
+		h.Write([]byte("Finalize"))
+		outputs[i] = h.Sum(nil)
+	}
+	return outputs, nil
+}
+
+// scalarMultEach returns {s*p : p in points}.
+func scalarMultEach(s *ristretto255.Scalar, points []*ristretto255.Element) []*ristretto255.Element {
+	out := make([]*ristretto255.Element, len(points))
+	for i, p := range points {
+		out[i] = new(ristretto255.Element).ScalarMult(s, p)
+	}
+	return out
+}
+
+// generateProof implements the batched DLEQ proof of RFC 9497 §2.2.1: it
+// proves knowledge of k such that A = k*G and C_i = k*B_i for every i,
+// using a single Fiat-Shamir challenge derived from a random linear
+// combination of all the (B_i, C_i) pairs.
+func generateProof(mode Mode, k *ristretto255.Scalar, A *ristretto255.Element, Bs, Cs []*ristretto255.Element) []byte {
+	M, Z := computeComposites(mode, Bs, Cs)
+
+	r := randomScalar()
+	rG := new(ristretto255.Element).ScalarBaseMult(r)
+	rM := new(ristretto255.Element).ScalarMult(r, M)
+
+	c := proofChallenge(mode, A, M, Z, rG, rM)
+
+	s := new(ristretto255.Scalar).Multiply(c, k)
+	s.Negate(s)
+	s.Add(s, r) // s = r - c*k
+
+	proof := make([]byte, 0, 64)
+	proof = append(proof, c.Bytes()...)
+	proof = append(proof, s.Bytes()...)
+	return proof
+}
+
+// verifyProof checks a proof produced by generateProof against the public
+// point A (e.g. the server's public key) and the claimed pairs.
+func verifyProof(mode Mode, A *ristretto255.Element, Bs, Cs []*ristretto255.Element, proof []byte) bool {
+	if len(proof) != 64 {
+		return false
+	}
+	c, err := new(ristretto255.Scalar).FromCanonicalBytes(proof[:32])
+	if err != nil {
+		return false
+	}
+	s, err := new(ristretto255.Scalar).FromCanonicalBytes(proof[32:])
+	if err != nil {
+		return false
+	}
+
+	M, Z := computeComposites(mode, Bs, Cs)
+
+	// r' = s*G + c*A, which equals r if the prover knew k with A = k*G.
+	rG := new(ristretto255.Element).Add(
+		new(ristretto255.Element).ScalarBaseMult(s),
+		new(ristretto255.Element).ScalarMult(c, A))
+	rM := new(ristretto255.Element).Add(
+		new(ristretto255.Element).ScalarMult(s, M),
+		new(ristretto255.Element).ScalarMult(c, Z))
+
+	expected := proofChallenge(mode, A, M, Z, rG, rM)
+	return c.Equal(expected) == 1
+}
+
+// computeComposites folds a list of (B_i, C_i) pairs into a single pair
+// (M, Z) using Fiat-Shamir coefficients derived from all the inputs, so
+// that one DLEQ proof can cover an arbitrary batch.
+func computeComposites(mode Mode, Bs, Cs []*ristretto255.Element) (M, Z *ristretto255.Element) {
+	h := sha512.New()
+	h.Write(dst("Seed", mode))
+	var countBuf [2]byte
+	binary.BigEndian.PutUint16(countBuf[:], uint16(len(Bs)))
+	h.Write(countBuf[:])
+	for i := range Bs {
+		h.Write(Bs[i].Encode(nil))
+		h.Write(Cs[i].Encode(nil))
+	}
+	seed := h.Sum(nil)
+
+	M = new(ristretto255.Element).Zero()
+	Z = new(ristretto255.Element).Zero()
+	for i := range Bs {
+		var idxBuf [2]byte
+		binary.BigEndian.PutUint16(idxBuf[:], uint16(i+1))
+
+		dh := sha512.New()
+		dh.Write(seed)
+		dh.Write(idxBuf[:])
+		di := ristretto255.HashToScalar(dh.Sum(nil), dst("Composite", mode))
+
+		M.Add(M, new(ristretto255.Element).ScalarMult(di, Bs[i]))
+		Z.Add(Z, new(ristretto255.Element).ScalarMult(di, Cs[i]))
+	}
+	return M, Z
+}
+
+func proofChallenge(mode Mode, A, M, Z, t2, t3 *ristretto255.Element) *ristretto255.Scalar {
+	h := sha512.New()
+	h.Write(A.Encode(nil))
+	h.Write(M.Encode(nil))
+	h.Write(Z.Encode(nil))
+	h.Write(t2.Encode(nil))
+	h.Write(t3.Encode(nil))
+	return ristretto255.HashToScalar(h.Sum(nil), dst("Challenge", mode))
+}
+
+// infoScalar binds the POPRF public info string to a scalar tweak on the
+// server's key, per RFC 9497 §3.3.1.
+func infoScalar(mode Mode, info []byte) *ristretto255.Scalar {
+	h := sha512.New()
+	h.Write([]byte("Context"))
+	writeLenPrefixed(h, info)
+	return ristretto255.HashToScalar(h.Sum(nil), dst("Info", mode))
+}
+
+func writeLenPrefixed(h hash.Hash, b []byte) {
+	var lenBuf [2]byte
+	binary.BigEndian.PutUint16(lenBuf[:], uint16(len(b)))
+	h.Write(lenBuf[:])
+	h.Write(b)
+}
+
+func randomScalar() *ristretto255.Scalar {
+	var b [64]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return new(ristretto255.Scalar).FromUniformBytes(b[:])
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vrf implements a verifiable random function in the style of
+// Perrin and Marlinspike's VXEdDSA, retargeted to the ristretto255
+// prime-order group so that no cofactor clearing is required.
+package vrf
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gtank/ristretto255"
+)
+
+const hashToPointDST = "ristretto255-VXEdDSA-SHA512-ELL2"
+
+// proof is Γ, followed by the Fiat-Shamir challenge c and response s, each
+// as a 32-byte encoding. Γ must travel with the proof because Verify needs
+// it to recompute the challenge, even though the VRF output is derived
+// from it rather than sent directly.
+const proofLen = 32 + 32 + 32
+
+// Prove computes the VRF output for msg under sk, along with a proof that
+// output was derived correctly from msg and the public key corresponding
+// to sk.
+func Prove(sk *ristretto255.Scalar, msg []byte) (output [64]byte, proof []byte) {
+	pk := new(ristretto255.Element).ScalarBaseMult(sk)
+
+	H := hashToElement(msg)
+	Gamma := new(ristretto255.Element).ScalarMult(sk, H)
+
+	k := nonce(sk, msg)
+	U := new(ristretto255.Element).ScalarBaseMult(k)
+	V := new(ristretto255.Element).ScalarMult(k, H)
+
+	c := challenge(pk, H, Gamma, U, V)
+
+	s := new(ristretto255.Scalar).Multiply(c, sk)
+	s.Add(s, k)
+
+	proof = make([]byte, 0, proofLen)
+	proof = Gamma.Encode(proof)
+	proof = append(proof, c.Bytes()...)
+	proof = append(proof, s.Bytes()...)
+
+	output = sha512.Sum512(Gamma.Encode(nil))
+	return output, proof
+}
+
+// Verify reports whether proof demonstrates that output is the correct VRF
+// output for msg under the public key pk.
+func Verify(pk *ristretto255.Element, msg []byte, output [64]byte, proof []byte) bool {
+	Gamma, c, s, err := decodeProof(proof)
+	if err != nil {
+		return false
+	}
+
+	H := hashToElement(msg)
+
+	// U' = s*G - c*pk
+	sG := new(ristretto255.Element).ScalarBaseMult(s)
+	cPK := new(ristretto255.Element).ScalarMult(c, pk)
+	U := new(ristretto255.Element).Subtract(sG, cPK)
+
+	// V' = s*H - c*Gamma
+	sH := new(ristretto255.Element).ScalarMult(s, H)
+	cGamma := new(ristretto255.Element).ScalarMult(c, Gamma)
+	V := new(ristretto255.Element).Subtract(sH, cGamma)
+
+	cPrime := challenge(pk, H, Gamma, U, V)
+	if c.Equal(cPrime) != 1 {
+		return false
+	}
+
+	wantOutput := sha512.Sum512(Gamma.Encode(nil))
+	return subtle.ConstantTimeCompare(wantOutput[:], output[:]) == 1
+}
+
+func decodeProof(proof []byte) (Gamma *ristretto255.Element, c, s *ristretto255.Scalar, err error) {
+	if len(proof) != proofLen {
+		return nil, nil, nil, errors.New("vrf: invalid proof length")
+	}
+
+	Gamma = new(ristretto255.Element)
+	if err := Gamma.Decode(proof[:32]); err != nil {
+		return nil, nil, nil, err
+	}
+	c, err = new(ristretto255.Scalar).FromCanonicalBytes(proof[32:64])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s, err = new(ristretto255.Scalar).FromCanonicalBytes(proof[64:96])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return Gamma, c, s, nil
+}
+
+// hashToElement hashes msg to a ristretto255 Element with domain
+// separation, for use as the per-message VRF base point.
+func hashToElement(msg []byte) *ristretto255.Element {
+	h := sha512.New()
+	h.Write([]byte(hashToPointDST))
+	h.Write(msg)
+
+	e := new(ristretto255.Element)
+	e.FromUniformBytes(h.Sum(nil))
+	return e
+}
+
+// nonce derives a deterministic per-message scalar from sk and msg, so that
+// Prove needs no external randomness.
+func nonce(sk *ristretto255.Scalar, msg []byte) *ristretto255.Scalar {
+	h := sha512.New()
+	h.Write(sk.Bytes())
+	h.Write(msg)
+	return new(ristretto255.Scalar).FromUniformBytes(h.Sum(nil))
+}
+
+// challenge computes the Fiat-Shamir challenge binding the public key, the
+// hashed message point, the VRF point, and the prover's commitments.
+func challenge(pk, H, Gamma, U, V *ristretto255.Element) *ristretto255.Scalar {
+	h := sha512.New()
+	h.Write(pk.Encode(nil))
+	h.Write(H.Encode(nil))
+	h.Write(Gamma.Encode(nil))
+	h.Write(U.Encode(nil))
+	h.Write(V.Encode(nil))
+	return new(ristretto255.Scalar).FromUniformBytes(h.Sum(nil))
+}
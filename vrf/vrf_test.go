@@ -0,0 +1,97 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Copyright 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vrf
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/gtank/ristretto255"
+)
+
+func randomScalarForTest(rnd *rand.Rand) *ristretto255.Scalar {
+	var b [64]byte
+	rnd.Read(b[:])
+	return new(ristretto255.Scalar).FromUniformBytes(b[:])
+}
+
+// TestProveVerifyRoundTrip checks that Verify accepts the output and proof
+// Prove produces, for a handful of keys and messages.
+func TestProveVerifyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20; i++ {
+		sk := randomScalarForTest(rnd)
+		pk := new(ristretto255.Element).ScalarBaseMult(sk)
+
+		msg := make([]byte, rnd.Intn(64))
+		rnd.Read(msg)
+
+		output, proof := Prove(sk, msg)
+		if !Verify(pk, msg, output, proof) {
+			t.Fatalf("Verify rejected a proof Prove just produced (msg=%x)", msg)
+		}
+	}
+}
+
+// TestProveDeterministic checks that Prove(sk, msg) is deterministic, since
+// it derives its nonce from sk and msg rather than using randomness.
+func TestProveDeterministic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	sk := randomScalarForTest(rnd)
+	msg := []byte("deterministic nonce")
+
+	output1, proof1 := Prove(sk, msg)
+	output2, proof2 := Prove(sk, msg)
+
+	if output1 != output2 {
+		t.Fatal("Prove produced different outputs for the same key and message")
+	}
+	if !bytes.Equal(proof1, proof2) {
+		t.Fatal("Prove produced different proofs for the same key and message")
+	}
+}
+
+// TestVerifyRejectsTampering checks that Verify rejects a proof or output
+// that doesn't match the message or key it was produced for.
+func TestVerifyRejectsTampering(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	sk := randomScalarForTest(rnd)
+	pk := new(ristretto255.Element).ScalarBaseMult(sk)
+	msg := []byte("the real message")
+
+	output, proof := Prove(sk, msg)
+	if !Verify(pk, msg, output, proof) {
+		t.Fatal("Verify rejected a valid proof")
+	}
+
+	if Verify(pk, []byte("a different message"), output, proof) {
+		t.Fatal("Verify accepted a proof for the wrong message")
+	}
+
+	otherSK := randomScalarForTest(rnd)
+	otherPK := new(ristretto255.Element).ScalarBaseMult(otherSK)
+	if Verify(otherPK, msg, output, proof) {
+		t.Fatal("Verify accepted a proof under the wrong public key")
+	}
+
+	badOutput := output
+	badOutput[0] ^= 1
+	if Verify(pk, msg, badOutput, proof) {
+		t.Fatal("Verify accepted a tampered output")
+	}
+
+	badProof := append([]byte{}, proof...)
+	badProof[len(badProof)-1] ^= 1
+	if Verify(pk, msg, output, badProof) {
+		t.Fatal("Verify accepted a tampered proof")
+	}
+
+	if Verify(pk, msg, output, proof[:len(proof)-1]) {
+		t.Fatal("Verify accepted a truncated proof")
+	}
+}